@@ -4,15 +4,20 @@ package main
 import (
 	"encoding/json"
 	"log"
+	"twitch-client/internal/automod"
 	"twitch-client/internal/bot"
+	"twitch-client/internal/chatlog"
 	twitch "twitch-client/internal/client"
 	"twitch-client/internal/config"
 	"twitch-client/internal/credentials"
 	"twitch-client/internal/db"
+	"twitch-client/internal/helix"
 	"twitch-client/internal/server"
 	socket "twitch-client/internal/server/websocket"
 	"twitch-client/internal/server/websocket/ratelimiter"
 	"twitch-client/internal/service"
+	"twitch-client/internal/service/poll"
+	"twitch-client/internal/timers"
 	"twitch-client/internal/trends"
 
 	twitchirc "github.com/gempir/go-twitch-irc/v4"
@@ -36,23 +41,57 @@ func main() {
 
 	rl := ratelimiter.NewRateLimiter(30)
 
-	creds := credentials.NewCredentialsManager()
+	if cfg.CredentialsKey == "" {
+		log.Println("CREDENTIALS_KEY is not set, credentials won't survive a restart")
+	}
+
+	accounts, err := credentials.NewAccounts(db, cfg.CredentialsKey)
+	if err != nil {
+		log.Fatalf("Failed to load credentials: %v", err)
+	}
 
 	soc := socket.NewWebSocket(&rl)
-	trendTracker := trends.NewTrendTracker(100)
-	twitchClient := twitch.NewClient(creds, nil)
+	channelTrends := trends.NewChannelTrends(100)
+	twitchClient := twitch.NewClient(accounts.Bot, nil)
 	defer twitchClient.Close() // Important: clean up subscription
 
 	go soc.Run()
 
-	b := bot.NewBot(trendTracker, soc, twitchClient, db)
+	chatLog := chatlog.NewWriter(db)
+	go chatLog.Run()
+	defer chatLog.Close()
+
+	botHelix := helix.NewClient(accounts.Bot, cfg.TwitchHelixURL)
+	broadcasterHelix := helix.NewClient(accounts.Broadcaster, cfg.TwitchHelixURL)
+	polls := poll.NewManager(db, soc, broadcasterHelix)
+
+	timerScheduler := timers.NewScheduler(db, twitchClient, botHelix)
+	go timerScheduler.Run()
+
+	b := bot.NewBot(channelTrends, soc, twitchClient, botHelix, accounts.Broadcaster, db, polls, timerScheduler)
 
 	twitchClient.MessageHandler = b.HandleMessage
 
-	svc := service.NewService(twitchClient, trendTracker, cfg, db, creds, b)
-	serv := server.NewServer(svc, soc, creds, cfg)
+	automodEngine := automod.NewEngine(db, botHelix.GetUserCreatedAt)
+	linkGuard := automod.NewLinkGuard(db)
+	raidGuard := automod.NewRaidGuard(db, soc)
+	go raidGuard.Run()
+
+	svc := service.NewService(twitchClient, channelTrends, cfg, db, accounts, botHelix, broadcasterHelix, b, polls, automodEngine, linkGuard, raidGuard, timerScheduler, soc)
+	serv := server.NewServer(svc, soc, accounts, cfg, db)
 
+	b.SetModeration(svc)
 	twitchClient.MessageInterceptor = svc.InterceptMessage
+	raidGuard.Protect = svc.ProtectChannel
+	raidGuard.Unprotect = svc.UnprotectChannel
+	twitchClient.MessageLogger = chatLog.Write
+
+	twitchClient.OnStateChange = func(status twitch.Status) {
+		if status.State == twitch.StateConnected {
+			raidGuard.Reconnected()
+		}
+		soc.BroadcastConnectionMessage(status)
+	}
 
 	twitchClient.OnUserJoin = func(message twitchirc.UserJoinMessage) {
 		stringMessage, err := json.Marshal(message)
@@ -60,7 +99,8 @@ func main() {
 			log.Printf("Failed to marshal user join message: %v", err)
 		}
 		log.Printf("User joined: %s", stringMessage)
-		soc.BroadcastUserJoinMessage(message.User)
+		soc.BroadcastUserJoinMessage(message.Channel, message.User)
+		raidGuard.TrackJoin(message.Channel, message.User)
 	}
 
 	twitchClient.OnUserPart = func(message twitchirc.UserPartMessage) {
@@ -70,7 +110,15 @@ func main() {
 		}
 
 		log.Printf("User left: %s", stringMessage)
-		soc.BroadcastUserPartMessage(message.User)
+		soc.BroadcastUserPartMessage(message.Channel, message.User)
+	}
+
+	if err := svc.RestoreChannels(); err != nil {
+		log.Printf("Failed to restore channels: %v", err)
+	}
+
+	if err := svc.RestoreChatSettingsReverts(); err != nil {
+		log.Printf("Failed to restore chat settings reverts: %v", err)
 	}
 
 	serv.Run()
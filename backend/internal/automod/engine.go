@@ -0,0 +1,223 @@
+package automod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"twitch-client/internal/db"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/roles"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+const (
+	// Account creation dates never change, they are kept until the cache fills up
+	maxCachedAccounts = 10000
+	lookupTimeout     = 5 * time.Second
+	// A failed lookup isn't retried for this long, so an outage doesn't cost every message a timeout
+	failedLookupTTL = time.Minute
+)
+
+// Sample is a made-up message to try a rule on
+type Sample struct {
+	Message        string `json:"message"`
+	FirstMessage   bool   `json:"first_message"`
+	Emotes         int    `json:"emotes"`
+	AccountAgeDays int    `json:"account_age_days"`
+}
+
+// Engine matches chat messages against the automod rules stored in Postgres
+type Engine struct {
+	db             *db.Database
+	accountCreated func(ctx context.Context, userID string) (time.Time, error)
+	rules          []*rule
+	phraseLists    map[int]*phraseMatcher
+	accounts       map[string]time.Time
+	// When the lookup of an account last failed
+	failedLookups map[string]time.Time
+	mu            sync.RWMutex
+}
+
+// NewEngine loads the rules, accountCreated looks up when a Twitch account was created
+func NewEngine(db *db.Database, accountCreated func(ctx context.Context, userID string) (time.Time, error)) *Engine {
+	e := &Engine{
+		db:             db,
+		accountCreated: accountCreated,
+		accounts:       make(map[string]time.Time),
+		failedLookups:  make(map[string]time.Time),
+	}
+
+	if err := e.Reload(); err != nil {
+		log.Printf("Failed to load automod rules: %v", err)
+	}
+
+	return e
+}
+
+// Reload replaces the rules and phrase lists with the ones stored in Postgres,
+// rules that no longer compile are skipped
+func (e *Engine) Reload() error {
+	stored, err := e.db.GetAutomodRules()
+	if err != nil {
+		return fmt.Errorf("failed to get automod rules: %w", err)
+	}
+
+	lists, err := e.db.GetPhraseLists()
+	if err != nil {
+		return fmt.Errorf("failed to get phrase lists: %w", err)
+	}
+
+	phraseLists := make(map[int]*phraseMatcher, len(lists))
+	for _, list := range lists {
+		phraseLists[list.ID] = newPhraseMatcher(list.Phrases)
+	}
+
+	rules := make([]*rule, 0, len(stored))
+	for _, r := range stored {
+		compiled, err := compile(r)
+		if err != nil {
+			log.Printf("Skipping automod rule %d (%s): %v", r.ID, r.Name, err)
+			continue
+		}
+
+		if id := r.Conditions.PhraseListID; id != 0 {
+			compiled.phrases = phraseLists[id]
+			if compiled.phrases == nil {
+				log.Printf("Automod rule %d (%s) refers to missing phrase list %d", r.ID, r.Name, id)
+			}
+		}
+
+		rules = append(rules, compiled)
+	}
+
+	e.mu.Lock()
+	e.rules = rules
+	e.phraseLists = phraseLists
+	e.mu.Unlock()
+
+	return nil
+}
+
+// Check returns the first rule the message breaks, rules are tried in priority
+// order. Moderators and the broadcaster are never checked. Rules on the
+// account age may look it up over Helix, so keep Check off the IRC goroutine.
+func (e *Engine) Check(message twitchirc.PrivateMessage) (models.AutomodRule, bool) {
+	if roles.FromBadges(message.User.Badges).AtLeast(roles.Moderator) {
+		return models.AutomodRule{}, false
+	}
+
+	emotes := 0
+	for _, emote := range message.Emotes {
+		emotes += emote.Count
+	}
+
+	msg := Message{
+		Channel:      message.Channel,
+		UserID:       message.User.ID,
+		Username:     message.User.Name,
+		Text:         message.Message,
+		FirstMessage: message.FirstMessage,
+		EmoteCount:   emotes,
+		Folded:       lazyFold(message.Message),
+		AccountAge: func() (time.Duration, error) {
+			return e.accountAge(message.User.ID)
+		},
+	}
+
+	e.mu.RLock()
+	rules := e.rules
+	e.mu.RUnlock()
+
+	for _, r := range rules {
+		if !r.appliesTo(msg.Channel) {
+			continue
+		}
+
+		if r.evaluate(msg, false).Matched {
+			return r.AutomodRule, true
+		}
+	}
+
+	return models.AutomodRule{}, false
+}
+
+// Test tries a rule on a sample message and reports every condition
+func (e *Engine) Test(r models.AutomodRule, sample Sample) (Result, error) {
+	compiled, err := compile(r)
+	if err != nil {
+		return Result{}, err
+	}
+
+	e.mu.RLock()
+	compiled.phrases = e.phraseLists[r.Conditions.PhraseListID]
+	e.mu.RUnlock()
+
+	msg := Message{
+		Text:         sample.Message,
+		FirstMessage: sample.FirstMessage,
+		EmoteCount:   sample.Emotes,
+		Folded:       lazyFold(sample.Message),
+		AccountAge: func() (time.Duration, error) {
+			return time.Duration(sample.AccountAgeDays) * 24 * time.Hour, nil
+		},
+	}
+
+	return compiled.evaluate(msg, true), nil
+}
+
+// lazyFold folds text on the first call only
+func lazyFold(text string) func() string {
+	var folded *string
+	return func() string {
+		if folded == nil {
+			f := Fold(text)
+			folded = &f
+		}
+		return *folded
+	}
+}
+
+var errLookupFailed = errors.New("account age lookup failed recently")
+
+func (e *Engine) accountAge(userID string) (time.Duration, error) {
+	e.mu.RLock()
+	created, ok := e.accounts[userID]
+	failedAt, failed := e.failedLookups[userID]
+	e.mu.RUnlock()
+
+	if ok {
+		return time.Since(created), nil
+	}
+	if failed && time.Since(failedAt) < failedLookupTTL {
+		return 0, errLookupFailed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+
+	created, err := e.accountCreated(ctx, userID)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Failed to look up account age of %s: %v", userID, err)
+		if len(e.failedLookups) >= maxCachedAccounts {
+			e.failedLookups = make(map[string]time.Time)
+		}
+		e.failedLookups[userID] = time.Now()
+		return 0, err
+	}
+
+	if len(e.accounts) >= maxCachedAccounts {
+		e.accounts = make(map[string]time.Time)
+	}
+	e.accounts[userID] = created
+	delete(e.failedLookups, userID)
+
+	return time.Since(created), nil
+}
@@ -0,0 +1,50 @@
+package automod
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEngineAccountAgeCache(t *testing.T) {
+	lookups := 0
+	lookupErr := errors.New("helix down")
+	fail := true
+
+	e := &Engine{
+		accountCreated: func(ctx context.Context, userID string) (time.Time, error) {
+			lookups++
+			if fail {
+				return time.Time{}, lookupErr
+			}
+			return time.Now().Add(-48 * time.Hour), nil
+		},
+		accounts:      make(map[string]time.Time),
+		failedLookups: make(map[string]time.Time),
+	}
+
+	if _, err := e.accountAge("1"); !errors.Is(err, lookupErr) {
+		t.Fatalf("expected the lookup error, got %v", err)
+	}
+
+	// A recent failure isn't looked up again
+	fail = false
+	if _, err := e.accountAge("1"); !errors.Is(err, errLookupFailed) || lookups != 1 {
+		t.Fatalf("expected the failure to be cached, got %v after %d lookups", err, lookups)
+	}
+
+	// Until it expires
+	e.failedLookups["1"] = time.Now().Add(-failedLookupTTL)
+	if age, err := e.accountAge("1"); err != nil || age < 47*time.Hour || lookups != 2 {
+		t.Fatalf("expected a new lookup, got %v, %v after %d lookups", age, err, lookups)
+	}
+
+	// Successful lookups are kept
+	if _, err := e.accountAge("1"); err != nil || lookups != 2 {
+		t.Errorf("expected the age to be cached, got %v after %d lookups", err, lookups)
+	}
+	if _, failed := e.failedLookups["1"]; failed {
+		t.Error("expected the failure to be forgotten")
+	}
+}
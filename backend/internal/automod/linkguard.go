@@ -0,0 +1,125 @@
+package automod
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"twitch-client/internal/db"
+	"twitch-client/internal/db/models"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+// LinkGuard catches links in chat unless their domain is allowed in the
+// channel, the author is exempt by badge or was given a !permit
+type LinkGuard struct {
+	db       *db.Database
+	settings map[string]models.LinkProtection
+	permits  map[string]time.Time // channel/user -> end of the permit
+	mu       sync.RWMutex
+}
+
+func NewLinkGuard(db *db.Database) *LinkGuard {
+	g := &LinkGuard{
+		db:       db,
+		settings: make(map[string]models.LinkProtection),
+		permits:  make(map[string]time.Time),
+	}
+
+	if err := g.Reload(); err != nil {
+		log.Printf("Failed to load link protection: %v", err)
+	}
+
+	return g
+}
+
+// ValidateLinkProtection checks that link protection settings can be stored
+func ValidateLinkProtection(settings models.LinkProtection) error {
+	return validateAction(settings.Action, settings.DurationSeconds)
+}
+
+// Reload replaces the settings with the ones stored in Postgres
+func (g *LinkGuard) Reload() error {
+	stored, err := g.db.GetLinkProtections()
+	if err != nil {
+		return fmt.Errorf("failed to get link protection: %w", err)
+	}
+
+	settings := make(map[string]models.LinkProtection, len(stored))
+	for _, s := range stored {
+		settings[s.Channel] = s
+	}
+
+	g.mu.Lock()
+	g.settings = settings
+	g.mu.Unlock()
+
+	return nil
+}
+
+// Permit lets a user post links in a channel for a while
+func (g *LinkGuard) Permit(channel, username string, duration time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for key, until := range g.permits {
+		if now.After(until) {
+			delete(g.permits, key)
+		}
+	}
+
+	g.permits[channel+"/"+strings.ToLower(username)] = now.Add(duration)
+}
+
+// Check returns the settings of the channel and the offending hosts when the
+// message contains a link that isn't allowed
+func (g *LinkGuard) Check(message twitchirc.PrivateMessage) (models.LinkProtection, []string, bool) {
+	g.mu.RLock()
+	settings, ok := g.settings[message.Channel]
+	permitted := time.Now().Before(g.permits[message.Channel+"/"+message.User.Name])
+	g.mu.RUnlock()
+
+	if !ok || !settings.Enabled || permitted || exempt(settings, message.User.Badges) {
+		return models.LinkProtection{}, nil, false
+	}
+
+	var blocked []string
+	for _, host := range detectLinks(message.Message) {
+		allowed := false
+		for _, domain := range settings.AllowedDomains {
+			if domainMatches(host, domain) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			blocked = append(blocked, host)
+		}
+	}
+
+	return settings, blocked, len(blocked) > 0
+}
+
+// exempt tells whether the badges allow posting links, the subscriber badge
+// of the first month has version 0 so badges are checked for presence
+func exempt(settings models.LinkProtection, badges map[string]int) bool {
+	has := func(badge string) bool {
+		_, ok := badges[badge]
+		return ok
+	}
+
+	switch {
+	case has("broadcaster"):
+		return true
+	case settings.ExemptModerators && has("moderator"):
+		return true
+	case settings.ExemptVIPs && has("vip"):
+		return true
+	case settings.ExemptSubscribers && (has("subscriber") || has("founder")):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package automod
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidDomain = errors.New("invalid domain")
+
+var (
+	// linkRegex finds bare domains as well as full URLs, the scheme is the first
+	// group and the host the second
+	linkRegex = regexp.MustCompile(`(?i)(https?://)?((?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,})(?::\d+)?(?:[/?#]\S*)?`)
+	// dotWordRegex finds dots spelled out like "example dot com" or "example[.]com"
+	dotWordRegex = regexp.MustCompile(`(?i)\s*[(\[{]\s*(?:\.|dot|kropka)\s*[)\]}]\s*|\s+(?:dot|kropka)\s+`)
+	// spacedDotRegex finds dots with spaces around them like "example . com"
+	spacedDotRegex = regexp.MustCompile(`\s+\.\s*|\.\s+`)
+	// removeSpaceRegex finds the hint spam bots leave next to a link split by a space
+	removeSpaceRegex = regexp.MustCompile(`(?i)remove\s+(?:the\s+)?spaces?|usu[nń]\s+spacj[eęi]`)
+)
+
+// knownTLDs keeps "end.Next sentence" from being taken for a link, hosts
+// without a scheme only count when they end with one of these
+var knownTLDs = map[string]bool{
+	"app": true, "be": true, "biz": true, "cc": true, "club": true, "co": true,
+	"com": true, "de": true, "dev": true, "eu": true, "fun": true, "gg": true,
+	"info": true, "io": true, "link": true, "live": true, "ly": true, "me": true,
+	"net": true, "online": true, "org": true, "pl": true, "ru": true, "shop": true,
+	"site": true, "store": true, "stream": true, "to": true, "top": true, "tv": true,
+	"uk": true, "us": true, "xyz": true,
+}
+
+// obfuscatedTLDs are the TLDs trusted once spaces were removed, TLDs that are
+// also common words like "to" or "co" would turn "ok. to" into a link
+var obfuscatedTLDs = map[string]bool{
+	"com": true, "gg": true, "io": true, "ly": true, "net": true, "org": true,
+	"pl": true, "ru": true, "tv": true, "xyz": true,
+}
+
+// extractDomains returns the lowercase hosts of all links in the text
+func extractDomains(text string) []string {
+	var domains []string
+	for _, match := range linkRegex.FindAllStringSubmatch(text, -1) {
+		domains = append(domains, strings.ToLower(match[2]))
+	}
+	return domains
+}
+
+// detectLinks returns the lowercase hosts of all links in the text including
+// obfuscated ones like "example dot com", "example . com" or "exam ple.com"
+// next to a "remove the space" hint
+func detectLinks(text string) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+
+	collect := func(candidate string, tlds map[string]bool) {
+		for _, match := range linkRegex.FindAllStringSubmatch(candidate, -1) {
+			host := strings.ToLower(match[2])
+			if match[1] == "" && !tlds[host[strings.LastIndex(host, ".")+1:]] {
+				continue
+			}
+			if !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+
+	collect(text, knownTLDs)
+
+	normalized := dotWordRegex.ReplaceAllString(text, ".")
+	normalized = spacedDotRegex.ReplaceAllString(normalized, ".")
+	if normalized != text {
+		collect(normalized, obfuscatedTLDs)
+	}
+
+	if removeSpaceRegex.MatchString(normalized) {
+		words := strings.Fields(removeSpaceRegex.ReplaceAllString(normalized, " "))
+		for i := 0; i+1 < len(words); i++ {
+			collect(words[i]+words[i+1], obfuscatedTLDs)
+		}
+	}
+
+	return hosts
+}
+
+// linksTo tells whether the text links to one of the domains or their
+// subdomains, the "*" domain matches any link
+func linksTo(text string, domains []string) bool {
+	for _, host := range extractDomains(text) {
+		for _, domain := range domains {
+			if domainMatches(host, domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func domainMatches(host, domain string) bool {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "*" {
+		return true
+	}
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// NormalizeDomain turns a domain or URL into the bare lowercase host, a
+// leading "www." is dropped so the allowlist covers every subdomain
+func NormalizeDomain(domain string) (string, error) {
+	match := linkRegex.FindStringSubmatch(strings.TrimSpace(domain))
+	if match == nil || len(match[0]) != len(strings.TrimSpace(domain)) {
+		return "", ErrInvalidDomain
+	}
+	return strings.TrimPrefix(strings.ToLower(match[2]), "www."), nil
+}
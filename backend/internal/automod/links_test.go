@@ -0,0 +1,84 @@
+package automod
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDetectLinks(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"check https://Example.com/path?x=1", []string{"example.com"}},
+		{"follow twitch.tv/streamer and youtube.com", []string{"twitch.tv", "youtube.com"}},
+		{"HTTP://SHOP.EXAMPLE.ORG:8080", []string{"shop.example.org"}},
+		{"example dot com", []string{"example.com"}},
+		{"example kropka pl", []string{"example.pl"}},
+		{"example[.]com", []string{"example.com"}},
+		{"example (dot) net", []string{"example.net"}},
+		{"example . com", []string{"example.com"}},
+		{"exa mple.com remove the space", []string{"mple.com", "example.com"}},
+		{"exa mple . gg usuń spacje", []string{"mple.gg", "example.gg"}},
+		{"same link example.com and example.com", []string{"example.com"}},
+
+		// Ordinary chat that looks a bit like a link
+		{"good game.Next time", nil},
+		{"ok. to be honest", nil},
+		{"1.5 hours left", nil},
+		{"wait... what", nil},
+		{"see you at 10.30", nil},
+		{"dot com bubble", nil},
+		{"gg wp", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := detectLinks(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("detectLinks(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinksTo(t *testing.T) {
+	tests := []struct {
+		text    string
+		domains []string
+		want    bool
+	}{
+		{"clips.twitch.tv/abc", []string{"twitch.tv"}, true},
+		{"https://twitch.tv", []string{"TWITCH.TV "}, true},
+		{"nottwitch.tv", []string{"twitch.tv"}, false},
+		{"example.com", []string{"*"}, true},
+		{"no links here", []string{"*"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := linksTo(tt.text, tt.domains); got != tt.want {
+			t.Errorf("linksTo(%q, %v) = %v, want %v", tt.text, tt.domains, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+		err    error
+	}{
+		{"www.YouTube.com", "youtube.com", nil},
+		{"https://clips.twitch.tv/abc", "clips.twitch.tv", nil},
+		{" example.pl ", "example.pl", nil},
+		{"not a domain", "", ErrInvalidDomain},
+		{"example.com and more", "", ErrInvalidDomain},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeDomain(tt.domain)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("NormalizeDomain(%q) = %q, %v, want %q, %v", tt.domain, got, err, tt.want, tt.err)
+		}
+	}
+}
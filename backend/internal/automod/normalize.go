@@ -0,0 +1,117 @@
+package automod
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldMap maps homoglyphs, accented letters and leetspeak to plain ASCII
+// letters, it is applied after lowercasing
+var foldMap = map[rune]rune{
+	// Leetspeak
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b',
+	'@': 'a', '$': 's', '|': 'l', '+': 't', '€': 'e',
+	// Polish and other accented letters
+	'ą': 'a', 'ć': 'c', 'ę': 'e', 'ł': 'l', 'ń': 'n', 'ó': 'o', 'ś': 's', 'ź': 'z', 'ż': 'z',
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ç': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'ñ': 'n', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ý': 'y', 'ÿ': 'y',
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'г': 'r', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j', 'ѕ': 's',
+	'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x',
+}
+
+// Normalize folds text like Fold and also collapses runs of one character
+// into one ("fr33ee" -> "fre"), so stretched spellings compare equal.
+func Normalize(text string) string {
+	return normalize(text, true)
+}
+
+// Fold makes look-alike spellings compare equal: case is folded, invisible
+// characters are dropped, homoglyphs and leetspeak become ASCII letters,
+// punctuation is dropped and whitespace becomes a single space. "!" is an "i"
+// only in front of a letter, at the end of a word it is punctuation. It makes
+// a single pass over the text as it runs for every chat message.
+func Fold(text string) string {
+	return normalize(text, false)
+}
+
+func normalize(text string, collapse bool) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	last := rune(-1)
+	space := false
+	// exclamation marks waiting to see whether a letter follows
+	bangs := 0
+	for _, r := range text {
+		if r >= 0x80 {
+			if unicode.In(r, unicode.Mn, unicode.Cf) {
+				continue
+			}
+			r = foldWide(r)
+		}
+
+		r = unicode.ToLower(r)
+		if folded, ok := foldMap[r]; ok {
+			r = folded
+		}
+
+		if r == '!' {
+			bangs++
+			continue
+		}
+		if unicode.IsSpace(r) {
+			space = b.Len() > 0
+			bangs = 0
+			continue
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			bangs = 0
+			continue
+		}
+
+		if space {
+			b.WriteByte(' ')
+			space = false
+			last = ' '
+		}
+		for ; bangs > 0; bangs-- {
+			if !collapse || last != 'i' {
+				b.WriteByte('i')
+				last = 'i'
+			}
+		}
+		if collapse && r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+
+	return b.String()
+}
+
+// foldWide maps fullwidth, circled and mathematical letters to ASCII
+func foldWide(r rune) rune {
+	switch {
+	case r >= 0xFF01 && r <= 0xFF5E: // Fullwidth ASCII
+		return r - 0xFF01 + '!'
+	case r >= 0x24B6 && r <= 0x24CF: // Circled capitals
+		return r - 0x24B6 + 'a'
+	case r >= 0x24D0 && r <= 0x24E9: // Circled small letters
+		return r - 0x24D0 + 'a'
+	case r >= 0x1D400 && r <= 0x1D6A3: // Mathematical alphanumerics, A-Z and a-z in every style
+		offset := (r - 0x1D400) % 52
+		if offset >= 26 {
+			offset -= 26
+		}
+		return 'a' + offset
+	}
+	return r
+}
@@ -0,0 +1,91 @@
+package automod
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello World", "helo world"},
+		{"FR33EE   f0ll0w3rs", "fre folowers"},
+		{"b\u200bu\u200dy", "buy"},
+		{"  spaced \t\n out  ", "spaced out"},
+		{"ｆｕｌｌｗｉｄｔｈ", "fulwidth"},
+		{"ⓒⓘⓡⓒⓛⓔⓓ", "circled"},
+		{"𝐛𝐨𝐥𝐝 𝓈𝒸𝓇𝒾𝓅𝓉", "bold script"},
+		{"zażółć gęślą", "zazolc gesla"},
+		{"сheар viewеrs", "cheap viewers"},
+		{"ñoño café", "nono cafe"},
+		{"éxample", "example"},
+		{"a.b-c_d", "abcd"},
+		{"", ""},
+		{"!!!", ""},
+		{"fr!end! nice!!", "friend nice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Normalize(tt.text); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldKeepsRepeats(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"FR33EE", "freeee"},
+		{"a$$", "ass"},
+		{"Hello   World", "hello world"},
+		{"ｂｏｏｋ", "book"},
+	}
+
+	for _, tt := range tests {
+		if got := Fold(tt.text); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// chatLines is a sample of ordinary and spammy chat used by the benchmarks
+var chatLines = []string{
+	"KEKW",
+	"gg wp that was insane",
+	"@streamer can you play the next map please? I've been waiting all stream",
+	"LUL LUL LUL LUL LUL",
+	"Buy ｆｏｌｌｏｗｅｒｓ, primes and viewers on ⓒⓗⓔⓐⓟ-viewers dot com",
+	"zażółć gęślą jaźń, jak tam stream? widzę że znowu ranked",
+	"Wanna become famous? Best viewers on example . com remove the space",
+	"ahahahahahahaha nooooooooooo what was that",
+	"pog pog pog pog pog pog pog pog pog pog pog pog pog pog pog pog pog pog",
+	"сheар viewеrs аnd fоllоwеrs hеrе",
+}
+
+func BenchmarkNormalize(b *testing.B) {
+	size := 0
+	for _, line := range chatLines {
+		size += len(line)
+	}
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for _, line := range chatLines {
+			Normalize(line)
+		}
+	}
+}
+
+func BenchmarkFold(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for _, line := range chatLines {
+			Fold(line)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package automod
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"twitch-client/internal/db/models"
+	"unicode"
+)
+
+var ErrInvalidPhrase = errors.New("phrase is empty once normalized")
+
+// phraseMatcher finds the phrases of a list in folded text. Phrases match
+// whole words only, so a banned "ass" doesn't hit "has" or "class", and a
+// letter repeated in the phrase has to be repeated in the text as well, so
+// "ass" doesn't hit "as" but still hits "a$$$".
+type phraseMatcher struct {
+	// pattern holds every phrase of the list in one expression
+	pattern *regexp.Regexp
+}
+
+// ValidatePhraseList checks that a phrase list can be stored
+func ValidatePhraseList(list models.PhraseList) error {
+	if strings.TrimSpace(list.Name) == "" {
+		return ErrInvalidName
+	}
+
+	for _, phrase := range list.Phrases {
+		if Normalize(strings.ReplaceAll(phrase, "*", "")) == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidPhrase, phrase)
+		}
+	}
+
+	return nil
+}
+
+func newPhraseMatcher(phrases []string) *phraseMatcher {
+	m := &phraseMatcher{}
+
+	var patterns []string
+	for _, phrase := range phrases {
+		if Fold(strings.ReplaceAll(phrase, "*", "")) == "" {
+			continue
+		}
+
+		// A wildcard stands for the rest of a word, so it never crosses a space
+		parts := strings.Split(phrase, "*")
+		for i, part := range parts {
+			parts[i] = runPattern(foldPart(part))
+		}
+		patterns = append(patterns, strings.Join(parts, `[^ ]*`))
+	}
+
+	if len(patterns) > 0 {
+		m.pattern = regexp.MustCompile(`(?:^| )(?:` + strings.Join(patterns, "|") + `)(?: |$)`)
+	}
+
+	return m
+}
+
+// foldPart folds the text around a wildcard, keeping the spaces at its ends
+// that Fold would trim
+func foldPart(part string) string {
+	folded := Fold(part)
+	if folded == "" {
+		return ""
+	}
+
+	if first := []rune(part)[0]; unicode.IsSpace(first) {
+		folded = " " + folded
+	}
+	if runes := []rune(part); unicode.IsSpace(runes[len(runes)-1]) {
+		folded += " "
+	}
+	return folded
+}
+
+// runPattern turns folded text into an expression where every run of a
+// letter matches at least as many of it, "free" becomes "f+r+e{2,}"
+func runPattern(folded string) string {
+	var b strings.Builder
+	runes := []rune(folded)
+	for i := 0; i < len(runes); {
+		n := 1
+		for i+n < len(runes) && runes[i+n] == runes[i] {
+			n++
+		}
+
+		switch {
+		case runes[i] == ' ':
+			b.WriteByte(' ')
+		case n == 1:
+			b.WriteString(regexp.QuoteMeta(string(runes[i])) + "+")
+		default:
+			fmt.Fprintf(&b, "%s{%d,}", regexp.QuoteMeta(string(runes[i])), n)
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// matches tells whether folded text contains one of the phrases
+func (m *phraseMatcher) matches(folded string) bool {
+	return m.pattern != nil && m.pattern.MatchString(folded)
+}
@@ -0,0 +1,99 @@
+package automod
+
+import (
+	"errors"
+	"testing"
+	"twitch-client/internal/db/models"
+)
+
+func TestPhraseMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		phrases []string
+		text    string
+		want    bool
+	}{
+		{"exact", []string{"ass"}, "you ass", true},
+		{"leetspeak", []string{"ass"}, "you a$$", true},
+		{"stretched", []string{"ass"}, "aaassssss!!", true},
+		{"punctuation inside", []string{"ass"}, "a.s.s", true},
+		{"homoglyphs", []string{"cheap viewers"}, "сheар viewеrs here", true},
+		{"fullwidth", []string{"follow"}, "ｆｏｌｌｏｗ me", true},
+		{"phrase written leet", []string{"fr33 f0llowers"}, "FREE followers", true},
+		{"case", []string{"Buy Followers"}, "BUY FOLLOWERS NOW", true},
+
+		// A collapsed "ass" is "as", it must not hit ordinary words
+		{"has", []string{"ass"}, "he has it", false},
+		{"was", []string{"ass"}, "that was close", false},
+		{"last", []string{"ass"}, "last game", false},
+		{"class", []string{"ass"}, "first class", false},
+		{"as", []string{"ass"}, "as well as", false},
+		{"assassin", []string{"ass"}, "assassin creed", false},
+		{"single letter missing", []string{"free"}, "fre stuff", false},
+		{"inside a word", []string{"cheap"}, "cheapest", false},
+
+		{"wildcard suffix", []string{"buy*"}, "buying viewers", true},
+		{"wildcard prefix", []string{"*ass"}, "badass move", true},
+		{"wildcard both sides", []string{"*ass*"}, "classic", true},
+		{"wildcard between words", []string{"buy * followers"}, "buy cheap followers", true},
+		{"wildcard stays in one word", []string{"buy * followers"}, "buy very cheap followers", false},
+		{"wildcard needs the rest", []string{"buy*"}, "bu", false},
+		{"several phrases", []string{"spam", "scam*"}, "total scammers", true},
+		{"empty phrases are skipped", []string{"", "*", "!?"}, "anything", false},
+		{"no phrases", nil, "anything", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newPhraseMatcher(tt.phrases)
+			if got := m.matches(Fold(tt.text)); got != tt.want {
+				t.Errorf("%v matching %q = %v, want %v", tt.phrases, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePhraseList(t *testing.T) {
+	if err := ValidatePhraseList(models.PhraseList{Name: "spam", Phrases: []string{"buy*", "cheap viewers"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidatePhraseList(models.PhraseList{Name: " ", Phrases: []string{"spam"}}); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("expected ErrInvalidName, got %v", err)
+	}
+	if err := ValidatePhraseList(models.PhraseList{Name: "spam", Phrases: []string{"*.*"}}); !errors.Is(err, ErrInvalidPhrase) {
+		t.Errorf("expected ErrInvalidPhrase, got %v", err)
+	}
+}
+
+func BenchmarkPhraseMatcher(b *testing.B) {
+	m := newPhraseMatcher([]string{
+		"cheap viewers", "buy followers", "buy * followers", "free primes",
+		"best viewers", "*viewers.com", "become famous", "ass", "scam*", "*bot*",
+		"onlyfans", "nudes", "discord.gg", "crypto giveaway", "free nitro",
+	})
+
+	folded := make([]string, len(chatLines))
+	for i, line := range chatLines {
+		folded[i] = Fold(line)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, line := range folded {
+			m.matches(line)
+		}
+	}
+}
+
+// BenchmarkPhraseCheck covers what a chat message costs a phrase rule, folding included
+func BenchmarkPhraseCheck(b *testing.B) {
+	m := newPhraseMatcher([]string{"cheap viewers", "buy * followers", "*bot*", "free nitro"})
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for _, line := range chatLines {
+			m.matches(Fold(line))
+		}
+	}
+}
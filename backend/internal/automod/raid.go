@@ -0,0 +1,349 @@
+package automod
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"twitch-client/internal/db"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/roles"
+	"twitch-client/internal/server/websocket"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+var ErrInvalidRaidProtection = errors.New("invalid raid protection settings")
+
+const (
+	RaidReasonJoins           = "joins"
+	RaidReasonSimilarMessages = "similar_messages"
+
+	joinWindow    = 10 * time.Second
+	messageWindow = 30 * time.Second
+	// Twitch sends the joins of everyone already in chat right after the bot joins
+	joinGracePeriod = time.Minute
+	// First messages this many bits apart count as near-identical
+	maxSimhashDistance = 6
+	// Shorter first messages like "hi" are left out, raiders greet alike too
+	minSimilarLength = 10
+	// Keeps a flood from growing the message window without bound
+	maxTrackedMessages = 500
+	raidCheckInterval  = 15 * time.Second
+	// Twitch allows followers-only durations of up to three months
+	maxFollowersMinutes = 129600
+)
+
+// Raid describes a spam-bot raid, it is sent to the dashboard when it starts and ends
+type Raid struct {
+	Channel   string     `json:"channel"`
+	Reason    string     `json:"reason"`
+	Count     int        `json:"count"`
+	Sample    string     `json:"sample,omitempty"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	// Protected tells whether chat was switched into the protective mode
+	Protected bool `json:"protected"`
+}
+
+// RaidGuard watches channels for bursts of joins and of near-identical first
+// messages. A burst starts a raid, the raid ends once the channel stayed calm
+// for the configured time.
+type RaidGuard struct {
+	db       *db.Database
+	socket   *websocket.WebSocket
+	settings map[string]models.RaidProtection
+	channels map[string]*raidState
+	mu       sync.Mutex
+
+	// Protect switches a channel into its protective mode and Unprotect
+	// switches it back, the guard works without them but only reports raids
+	Protect   func(settings models.RaidProtection) error
+	Unprotect func(channel string)
+}
+
+type raidState struct {
+	since     time.Time
+	joins     []time.Time
+	messages  []firstMessage
+	raid      *Raid
+	lastBurst time.Time
+}
+
+type firstMessage struct {
+	at   time.Time
+	user string
+	hash uint64
+}
+
+func NewRaidGuard(db *db.Database, socket *websocket.WebSocket) *RaidGuard {
+	g := &RaidGuard{
+		db:       db,
+		socket:   socket,
+		settings: make(map[string]models.RaidProtection),
+		channels: make(map[string]*raidState),
+	}
+
+	if err := g.Reload(); err != nil {
+		log.Printf("Failed to load raid protection: %v", err)
+	}
+
+	return g
+}
+
+// ValidateRaidProtection checks that raid protection settings can be stored
+func ValidateRaidProtection(settings models.RaidProtection) error {
+	switch {
+	case settings.Mode != models.RaidModeFollowers && settings.Mode != models.RaidModeEmote:
+		return fmt.Errorf("%w: mode must be %s or %s", ErrInvalidRaidProtection, models.RaidModeFollowers, models.RaidModeEmote)
+	case settings.FollowersDurationMinutes < 0 || settings.FollowersDurationMinutes > maxFollowersMinutes:
+		return fmt.Errorf("%w: followers duration must be between 0 and %d minutes", ErrInvalidRaidProtection, maxFollowersMinutes)
+	case settings.JoinThreshold < 2 || settings.SimilarThreshold < 2:
+		return fmt.Errorf("%w: thresholds must be at least 2", ErrInvalidRaidProtection)
+	case settings.CalmMinutes < 1 || settings.CalmMinutes > 60:
+		return fmt.Errorf("%w: calm time must be between 1 and 60 minutes", ErrInvalidRaidProtection)
+	}
+	return nil
+}
+
+// Reload replaces the settings with the ones stored in Postgres
+func (g *RaidGuard) Reload() error {
+	stored, err := g.db.GetRaidProtections()
+	if err != nil {
+		return fmt.Errorf("failed to get raid protection: %w", err)
+	}
+
+	settings := make(map[string]models.RaidProtection, len(stored))
+	for _, s := range stored {
+		settings[s.Channel] = s
+	}
+
+	g.mu.Lock()
+	g.settings = settings
+	g.mu.Unlock()
+
+	return nil
+}
+
+// Status returns the ongoing raid of a channel
+func (g *RaidGuard) Status(channel string) (Raid, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state, ok := g.channels[channel]
+	if !ok || state.raid == nil {
+		return Raid{}, false
+	}
+	return *state.raid, true
+}
+
+// Remove forgets a channel the bot left, a protective mode is switched back
+func (g *RaidGuard) Remove(channel string) {
+	g.mu.Lock()
+	state, ok := g.channels[channel]
+	delete(g.channels, channel)
+	g.mu.Unlock()
+
+	if ok && state.raid != nil && state.raid.Protected {
+		go g.unprotect(channel)
+	}
+}
+
+// Reconnected restarts the join grace period of every channel, after a
+// reconnect Twitch sends the joins of everyone in chat once more. Ongoing
+// raids carry on.
+func (g *RaidGuard) Reconnected() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for _, state := range g.channels {
+		state.since = now
+		state.joins = nil
+	}
+}
+
+// TrackJoin counts a user joining chat
+func (g *RaidGuard) TrackJoin(channel, username string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	state := g.stateLocked(channel, now)
+	if now.Sub(state.since) < joinGracePeriod {
+		return
+	}
+
+	state.joins = append(prune(state.joins, now.Add(-joinWindow)), now)
+
+	if count := len(state.joins); count >= g.settingsLocked(channel).JoinThreshold {
+		g.burstLocked(channel, state, RaidReasonJoins, count, "")
+	}
+}
+
+// TrackMessage compares the first message of a chatter with the other recent
+// first messages, moderators are left out
+func (g *RaidGuard) TrackMessage(message twitchirc.PrivateMessage) {
+	if !message.FirstMessage || roles.FromBadges(message.User.Badges).AtLeast(roles.Moderator) {
+		return
+	}
+
+	normalized := Normalize(message.Message)
+	if len([]rune(normalized)) < minSimilarLength {
+		return
+	}
+	hash := simhash(normalized)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	state := g.stateLocked(message.Channel, now)
+
+	cutoff := now.Add(-messageWindow)
+	kept := state.messages[:0]
+	for _, m := range state.messages {
+		if m.at.After(cutoff) {
+			kept = append(kept, m)
+		}
+	}
+	if len(kept) >= maxTrackedMessages {
+		kept = kept[1:]
+	}
+	state.messages = append(kept, firstMessage{at: now, user: message.User.ID, hash: hash})
+
+	users := make(map[string]bool)
+	for _, m := range state.messages {
+		if simhashDistance(m.hash, hash) <= maxSimhashDistance {
+			users[m.user] = true
+		}
+	}
+
+	if count := len(users); count >= g.settingsLocked(message.Channel).SimilarThreshold {
+		g.burstLocked(message.Channel, state, RaidReasonSimilarMessages, count, message.Message)
+	}
+}
+
+// Run ends raids once their channel calmed down, it never returns
+func (g *RaidGuard) Run() {
+	ticker := time.NewTicker(raidCheckInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		g.mu.Lock()
+		var ended []Raid
+		for channel, state := range g.channels {
+			calm := time.Duration(g.settingsLocked(channel).CalmMinutes) * time.Minute
+			if state.raid != nil && now.Sub(state.lastBurst) >= calm {
+				ended = append(ended, *state.raid)
+				state.raid = nil
+			}
+		}
+		g.mu.Unlock()
+
+		for _, raid := range ended {
+			g.end(raid, now)
+		}
+	}
+}
+
+func (g *RaidGuard) stateLocked(channel string, now time.Time) *raidState {
+	state, ok := g.channels[channel]
+	if !ok {
+		state = &raidState{since: now}
+		g.channels[channel] = state
+	}
+	return state
+}
+
+func (g *RaidGuard) settingsLocked(channel string) models.RaidProtection {
+	if settings, ok := g.settings[channel]; ok {
+		return settings
+	}
+	return db.DefaultRaidProtection(channel)
+}
+
+// burstLocked keeps an ongoing raid going or starts a new one
+func (g *RaidGuard) burstLocked(channel string, state *raidState, reason string, count int, sample string) {
+	state.lastBurst = time.Now()
+
+	if state.raid != nil {
+		if count > state.raid.Count {
+			state.raid.Count = count
+		}
+		return
+	}
+
+	raid := &Raid{
+		Channel:   channel,
+		Reason:    reason,
+		Count:     count,
+		Sample:    sample,
+		StartedAt: state.lastBurst,
+	}
+	state.raid = raid
+
+	// Helix calls would hold up the IRC client
+	go g.start(raid, g.settingsLocked(channel))
+}
+
+func (g *RaidGuard) start(raid *Raid, settings models.RaidProtection) {
+	log.Printf("Raid detected in %s: %d %s", raid.Channel, raid.Count, raid.Reason)
+
+	protected := false
+	if settings.Enabled && g.Protect != nil {
+		if err := g.Protect(settings); err != nil {
+			log.Printf("Failed to protect %s from a raid: %v", raid.Channel, err)
+		} else {
+			protected = true
+		}
+	}
+
+	g.mu.Lock()
+	state, ok := g.channels[raid.Channel]
+	current := ok && state.raid == raid
+	if current {
+		raid.Protected = protected
+	}
+	alert := *raid
+	g.mu.Unlock()
+
+	// The raid ended or the channel was left while Twitch was being called,
+	// nobody else is going to switch the mode back
+	if !current {
+		if protected {
+			g.unprotect(raid.Channel)
+		}
+		return
+	}
+
+	g.socket.BroadcastChannelEvent(raid.Channel, websocket.RaidStartEvent, alert)
+}
+
+func (g *RaidGuard) end(raid Raid, now time.Time) {
+	log.Printf("Raid in %s is over", raid.Channel)
+
+	if raid.Protected {
+		g.unprotect(raid.Channel)
+	}
+
+	raid.EndedAt = &now
+	g.socket.BroadcastChannelEvent(raid.Channel, websocket.RaidEndEvent, raid)
+}
+
+func (g *RaidGuard) unprotect(channel string) {
+	if g.Unprotect != nil {
+		g.Unprotect(channel)
+	}
+}
+
+// prune drops the times before cutoff, times are in order
+func prune(times []time.Time, cutoff time.Time) []time.Time {
+	for i, t := range times {
+		if t.After(cutoff) {
+			return times[i:]
+		}
+	}
+	return times[:0]
+}
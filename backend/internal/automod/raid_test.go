@@ -0,0 +1,190 @@
+package automod
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"twitch-client/internal/db"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/server/websocket"
+	"twitch-client/internal/server/websocket/ratelimiter"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+func newTestRaidGuard(t *testing.T, settings ...models.RaidProtection) *RaidGuard {
+	t.Helper()
+
+	rl := ratelimiter.NewRateLimiter(time.Second)
+	socket := websocket.NewWebSocket(&rl)
+	go socket.Run()
+
+	g := &RaidGuard{
+		socket:   socket,
+		settings: make(map[string]models.RaidProtection),
+		channels: make(map[string]*raidState),
+	}
+	for _, s := range settings {
+		g.settings[s.Channel] = s
+	}
+	return g
+}
+
+// joinBurst sends count joins to a channel whose grace period is over
+func joinBurst(g *RaidGuard, channel string, count int) {
+	g.mu.Lock()
+	g.stateLocked(channel, time.Now()).since = time.Now().Add(-2 * joinGracePeriod)
+	g.mu.Unlock()
+
+	for i := 0; i < count; i++ {
+		g.TrackJoin(channel, fmt.Sprintf("bot%d", i))
+	}
+}
+
+func TestSimhash(t *testing.T) {
+	spam := Normalize("Wanna become famous? Buy followers on cheapviewers dot com")
+
+	similar := []string{
+		"Wanna become famous? Buy followers on cheapviewers dot com !",
+		"wanna become FAMOUS? buy followers on cheapviewers dot com",
+		"Wanna becone famous? Buy follovers on cheapviewers dot com",
+	}
+	for _, text := range similar {
+		if d := simhashDistance(simhash(spam), simhash(Normalize(text))); d > maxSimhashDistance {
+			t.Errorf("expected %q to be near-identical, distance %d", text, d)
+		}
+	}
+
+	different := []string{
+		"hi everyone, first time here, loving the stream so far",
+		"what rank are you on this account? looks like diamond",
+		"Buy cheap primes and subs on another site entirely today",
+	}
+	for _, text := range different {
+		if d := simhashDistance(simhash(spam), simhash(Normalize(text))); d <= maxSimhashDistance {
+			t.Errorf("expected %q to differ, distance %d", text, d)
+		}
+	}
+
+	if simhash("ab") == 0 {
+		t.Error("expected short texts to get a fingerprint")
+	}
+}
+
+func TestRaidGuardJoinBurst(t *testing.T) {
+	g := newTestRaidGuard(t)
+
+	// Right after joining, the joins of everyone in chat are ignored
+	for i := 0; i < 50; i++ {
+		g.TrackJoin("streamer", fmt.Sprintf("viewer%d", i))
+	}
+	if _, ok := g.Status("streamer"); ok {
+		t.Fatal("expected no raid during the grace period")
+	}
+
+	joinBurst(g, "streamer", 30)
+	raid, ok := g.Status("streamer")
+	if !ok || raid.Reason != RaidReasonJoins || raid.Count != 30 {
+		t.Fatalf("expected a join raid, got %+v (%v)", raid, ok)
+	}
+}
+
+func TestRaidGuardReconnectGracePeriod(t *testing.T) {
+	g := newTestRaidGuard(t)
+
+	joinBurst(g, "streamer", 10)
+	g.Reconnected()
+
+	// Twitch repeats the joins of everyone in chat after the reconnect
+	for i := 0; i < 50; i++ {
+		g.TrackJoin("streamer", fmt.Sprintf("viewer%d", i))
+	}
+	if raid, ok := g.Status("streamer"); ok {
+		t.Fatalf("expected the reconnect joins to be ignored, got %+v", raid)
+	}
+}
+
+func TestRaidGuardSimilarMessages(t *testing.T) {
+	g := newTestRaidGuard(t)
+
+	for i := 0; i < 5; i++ {
+		g.TrackMessage(twitchirc.PrivateMessage{
+			Channel:      "streamer",
+			User:         twitchirc.User{ID: fmt.Sprint(i), Name: fmt.Sprintf("bot%d", i)},
+			Message:      fmt.Sprintf("Best viewers on cheapviewers dot com %d", i%2),
+			FirstMessage: true,
+		})
+	}
+
+	raid, ok := g.Status("streamer")
+	if !ok || raid.Reason != RaidReasonSimilarMessages || raid.Count != 5 {
+		t.Fatalf("expected a similar messages raid, got %+v (%v)", raid, ok)
+	}
+}
+
+func TestRaidGuardRestoresWhenRaidEndedDuringProtect(t *testing.T) {
+	settings := db.DefaultRaidProtection("streamer")
+	settings.Enabled = true
+	g := newTestRaidGuard(t, settings)
+
+	protecting := make(chan struct{})
+	proceed := make(chan struct{})
+	restored := make(chan string)
+	g.Protect = func(models.RaidProtection) error {
+		close(protecting)
+		<-proceed
+		return nil
+	}
+	g.Unprotect = func(channel string) {
+		restored <- channel
+	}
+
+	joinBurst(g, "streamer", 30)
+	<-protecting
+
+	// The raid ends, like Run does after the calm time, before Protect returned
+	g.mu.Lock()
+	g.channels["streamer"].raid = nil
+	g.mu.Unlock()
+	close(proceed)
+
+	select {
+	case channel := <-restored:
+		if channel != "streamer" {
+			t.Errorf("expected streamer to be switched back, got %s", channel)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the protective mode to be switched back")
+	}
+}
+
+func TestRaidGuardUnprotectsWhenRaidEnds(t *testing.T) {
+	settings := db.DefaultRaidProtection("streamer")
+	settings.Enabled = true
+	g := newTestRaidGuard(t, settings)
+
+	protected := make(chan struct{})
+	var restored []string
+	g.Protect = func(models.RaidProtection) error {
+		close(protected)
+		return nil
+	}
+	g.Unprotect = func(channel string) {
+		restored = append(restored, channel)
+	}
+
+	joinBurst(g, "streamer", 30)
+	<-protected
+	for {
+		if raid, _ := g.Status("streamer"); raid.Protected {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	raid, _ := g.Status("streamer")
+	g.end(raid, time.Now())
+	if len(restored) != 1 || restored[0] != "streamer" {
+		t.Errorf("expected the protective mode to be switched back once, got %v", restored)
+	}
+}
@@ -0,0 +1,222 @@
+package automod
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"twitch-client/internal/db/models"
+	"unicode"
+)
+
+var (
+	ErrInvalidName      = errors.New("rule name is required")
+	ErrInvalidAction    = errors.New("invalid rule action")
+	ErrInvalidDuration  = errors.New("timeout duration must be between 1 and 1209600 seconds")
+	ErrNoConditions     = errors.New("rule has no conditions")
+	ErrInvalidPattern   = errors.New("invalid rule pattern")
+	ErrInvalidCapsRatio = errors.New("caps ratio must be between 0 and 1")
+)
+
+const (
+	ActionDelete  = "delete"
+	ActionTimeout = "timeout"
+	ActionBan     = "ban"
+	ActionWarn    = "warn"
+
+	// Twitch allows timeouts of up to two weeks
+	maxTimeoutSeconds = 1209600
+	// Short messages like "OK" are never caps spam
+	defaultCapsMinLength = 10
+)
+
+// Condition names as reported by Result
+const (
+	ConditionPattern       = "pattern"
+	ConditionLinkDomains   = "link_domains"
+	ConditionPhraseList    = "phrase_list"
+	ConditionCapsRatio     = "caps_ratio"
+	ConditionMaxEmotes     = "max_emotes"
+	ConditionRepeatedChars = "repeated_chars"
+	ConditionFirstMessage  = "first_message"
+	ConditionAccountAge    = "max_account_age_days"
+)
+
+// Message is what rules are matched against
+type Message struct {
+	Channel      string
+	UserID       string
+	Username     string
+	Text         string
+	FirstMessage bool
+	EmoteCount   int
+	// Folded returns Text folded by Fold, it is only computed when a rule needs it
+	Folded func() string
+	// AccountAge is only looked up when a rule needs it
+	AccountAge func() (time.Duration, error)
+}
+
+// Result tells whether a rule matched and how each of its conditions did
+type Result struct {
+	Matched    bool            `json:"matched"`
+	Conditions map[string]bool `json:"conditions"`
+}
+
+// rule is a stored rule with its pattern compiled
+type rule struct {
+	models.AutomodRule
+	pattern *regexp.Regexp
+	// phrases is the phrase list of the rule, set by the engine
+	phrases *phraseMatcher
+}
+
+// Validate checks that a rule can be stored
+func Validate(r models.AutomodRule) error {
+	_, err := compile(r)
+	return err
+}
+
+func compile(r models.AutomodRule) (*rule, error) {
+	if strings.TrimSpace(r.Name) == "" {
+		return nil, ErrInvalidName
+	}
+
+	if err := validateAction(r.Action, r.DurationSeconds); err != nil {
+		return nil, err
+	}
+
+	c := r.Conditions
+	if c.Pattern == "" && len(c.LinkDomains) == 0 && c.PhraseListID == 0 && c.CapsRatio == 0 && c.MaxEmotes == 0 &&
+		c.RepeatedChars == 0 && !c.FirstMessage && c.MaxAccountAgeDays == 0 {
+		return nil, ErrNoConditions
+	}
+
+	if c.CapsRatio < 0 || c.CapsRatio > 1 {
+		return nil, ErrInvalidCapsRatio
+	}
+
+	compiled := &rule{AutomodRule: r}
+	if c.Pattern != "" {
+		pattern, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, errors.Join(ErrInvalidPattern, err)
+		}
+		compiled.pattern = pattern
+	}
+
+	return compiled, nil
+}
+
+// validateAction checks an action and, for timeouts, its duration
+func validateAction(action string, duration int) error {
+	switch action {
+	case ActionDelete, ActionBan, ActionWarn:
+	case ActionTimeout:
+		if duration < 1 || duration > maxTimeoutSeconds {
+			return ErrInvalidDuration
+		}
+	default:
+		return ErrInvalidAction
+	}
+	return nil
+}
+
+// appliesTo tells whether the rule covers the channel, rules without a channel cover all of them
+func (r *rule) appliesTo(channel string) bool {
+	return r.Enabled && (r.Channel == "" || r.Channel == channel)
+}
+
+// evaluate matches the message against every condition of the rule. Unless
+// all is set it stops at the first condition that fails, the cheap checks go
+// first so the account age is looked up only when everything else matched.
+func (r *rule) evaluate(msg Message, all bool) Result {
+	c := r.Conditions
+	result := Result{Matched: true, Conditions: make(map[string]bool)}
+
+	check := func(name string, enabled bool, matches func() bool) {
+		if !enabled || (!result.Matched && !all) {
+			return
+		}
+
+		matched := matches()
+		result.Conditions[name] = matched
+		result.Matched = result.Matched && matched
+	}
+
+	check(ConditionFirstMessage, c.FirstMessage, func() bool {
+		return msg.FirstMessage
+	})
+	check(ConditionPattern, r.pattern != nil, func() bool {
+		return r.pattern.MatchString(msg.Text)
+	})
+	check(ConditionLinkDomains, len(c.LinkDomains) > 0, func() bool {
+		return linksTo(msg.Text, c.LinkDomains)
+	})
+	check(ConditionPhraseList, c.PhraseListID != 0, func() bool {
+		return r.phrases != nil && r.phrases.matches(msg.Folded())
+	})
+	check(ConditionCapsRatio, c.CapsRatio > 0, func() bool {
+		minLength := c.MinLength
+		if minLength <= 0 {
+			minLength = defaultCapsMinLength
+		}
+		return capsRatio(msg.Text, minLength) >= c.CapsRatio
+	})
+	check(ConditionMaxEmotes, c.MaxEmotes > 0, func() bool {
+		return msg.EmoteCount > c.MaxEmotes
+	})
+	check(ConditionRepeatedChars, c.RepeatedChars > 0, func() bool {
+		return longestRun(msg.Text) >= c.RepeatedChars
+	})
+	check(ConditionAccountAge, c.MaxAccountAgeDays > 0, func() bool {
+		if msg.AccountAge == nil {
+			return false
+		}
+		age, err := msg.AccountAge()
+		if err != nil {
+			return false
+		}
+		return age < time.Duration(c.MaxAccountAgeDays)*24*time.Hour
+	})
+
+	return result
+}
+
+// capsRatio returns the share of uppercase letters, 0 for messages with fewer than minLength letters
+func capsRatio(text string, minLength int) float64 {
+	letters, upper := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+
+	if letters < minLength {
+		return 0
+	}
+	return float64(upper) / float64(letters)
+}
+
+// longestRun returns the length of the longest run of one character, spaces don't count
+func longestRun(text string) int {
+	longest, run := 0, 0
+	var previous rune
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		if r == previous && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		previous = r
+
+		if run > longest && !unicode.IsSpace(r) {
+			longest = run
+		}
+	}
+	return longest
+}
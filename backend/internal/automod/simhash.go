@@ -0,0 +1,46 @@
+package automod
+
+import (
+	"hash/fnv"
+	"math/bits"
+)
+
+// simhash fingerprints a normalized message so that near-identical messages
+// differ in few bits, the features are its character trigrams
+func simhash(normalized string) uint64 {
+	runes := []rune(normalized)
+
+	var weights [64]int
+	add := func(feature string) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for bit := range weights {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	if len(runes) < 3 {
+		add(normalized)
+	}
+	for i := 0; i+3 <= len(runes); i++ {
+		add(string(runes[i : i+3]))
+	}
+
+	var hash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash
+}
+
+// simhashDistance is the number of bits two fingerprints differ in
+func simhashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
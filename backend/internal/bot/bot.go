@@ -4,30 +4,36 @@ import (
 	"log"
 	"twitch-client/internal/bot/handler"
 	"twitch-client/internal/client"
+	"twitch-client/internal/credentials"
 	"twitch-client/internal/db"
 	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
 	socket "twitch-client/internal/server/websocket"
+	"twitch-client/internal/service/poll"
+	"twitch-client/internal/timers"
 	"twitch-client/internal/trends"
 
 	twitchirc "github.com/gempir/go-twitch-irc/v4"
 )
 
 type Bot struct {
-	tt             *trends.TrendTracker
+	trends         *trends.ChannelTrends
 	socket         *socket.WebSocket
 	twitchClient   *client.Client
 	db             *db.Database
 	commandHandler *handler.CommandHandler
+	timers         *timers.Scheduler
 }
 
-func NewBot(trendTracker *trends.TrendTracker, socket *socket.WebSocket, twitchClient *client.Client, db *db.Database) *Bot {
+func NewBot(channelTrends *trends.ChannelTrends, socket *socket.WebSocket, twitchClient *client.Client, botHelix *helix.Client, broadcaster *credentials.Credentials, db *db.Database, polls *poll.Manager, timers *timers.Scheduler) *Bot {
 	b := &Bot{
-		tt:           trendTracker,
+		trends:       channelTrends,
 		socket:       socket,
 		twitchClient: twitchClient,
 		db:           db,
+		timers:       timers,
 	}
-	b.commandHandler = handler.NewCommandHandler(db, twitchClient, socket, "!")
+	b.commandHandler = handler.NewCommandHandler(db, twitchClient, botHelix, broadcaster, socket, polls, "!")
 
 	return b
 }
@@ -47,10 +53,21 @@ func (b *Bot) HandleMessage(message twitchirc.PrivateMessage) {
 
 	b.commandHandler.HandleCommand(message)
 
-	b.tt.TrackMessage(username, message.Message, emotesConverted)
-	log.Printf("[%s] %s: %s", b.twitchClient.GetCurrentChannel(), username, message.Message)
+	b.trends.For(message.Channel).TrackMessage(username, message.Message, emotesConverted)
+	b.timers.TrackMessage(message.Channel)
+	log.Printf("[%s] %s: %s", message.Channel, username, message.Message)
 }
 
-func (b *Bot) GetAllCommands() ([]models.Command, error) {
-	return b.commandHandler.GetAllCommands()
+// InvalidateDashboardRoles makes chat commands pick up changed dashboard roles
+func (b *Bot) InvalidateDashboardRoles() {
+	b.commandHandler.InvalidateDashboardRoles()
+}
+
+// SetModeration enables the moderation chat commands
+func (b *Bot) SetModeration(moderation handler.Moderation) {
+	b.commandHandler.SetModeration(moderation)
+}
+
+func (b *Bot) GetAllCommands(channel string) ([]models.Command, error) {
+	return b.commandHandler.GetAllCommands(channel)
 }
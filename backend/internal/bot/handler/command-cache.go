@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"twitch-client/internal/db/models"
+)
+
+// Channels are loaded again after this long in case a change notification got lost
+const commandCacheTTL = 10 * time.Minute
+
+type commandStore interface {
+	GetChannelCommands(channel string) ([]models.Command, error)
+}
+
+// commandCache keeps the commands of every channel by name and alias, so chat
+// commands don't hit the database. Changes made by any backend instance
+// invalidate it through LISTEN/NOTIFY. Lookups resolve like
+// db.GetCommandByName: a channel command beats a shared one, then a name
+// beats an alias.
+type commandCache struct {
+	store    commandStore
+	channels map[string]*channelCommands
+	// Bumped by Invalidate, so a load that raced with it doesn't store what it read before
+	generations map[string]uint64
+	generation  uint64
+	mu          sync.RWMutex
+}
+
+type channelCommands struct {
+	byName   map[string]models.Command
+	loadedAt time.Time
+}
+
+func newCommandCache(store commandStore) *commandCache {
+	return &commandCache{
+		store:       store,
+		channels:    make(map[string]*channelCommands),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Get looks a command of a channel up by name or alias, shared commands included
+func (c *commandCache) Get(channel, name string) (models.Command, bool, error) {
+	c.mu.RLock()
+	commands, ok := c.channels[channel]
+	c.mu.RUnlock()
+
+	if !ok || time.Since(commands.loadedAt) > commandCacheTTL {
+		var err error
+		if commands, err = c.load(channel); err != nil {
+			return models.Command{}, false, err
+		}
+	}
+
+	cmd, ok := commands.byName[name]
+	return cmd, ok, nil
+}
+
+func (c *commandCache) load(channel string) (*channelCommands, error) {
+	c.mu.RLock()
+	generation, channelGeneration := c.generation, c.generations[channel]
+	c.mu.RUnlock()
+
+	stored, err := c.store.GetChannelCommands(channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commands: %w", err)
+	}
+
+	// Shared commands go in first so channel commands overwrite them, and
+	// within each the names go in last so they beat the aliases
+	commands := &channelCommands{byName: make(map[string]models.Command), loadedAt: time.Now()}
+	for _, shared := range []bool{true, false} {
+		for _, cmd := range stored {
+			if (cmd.Channel == "") == shared {
+				for _, alias := range cmd.Aliases {
+					commands.byName[alias] = cmd
+				}
+			}
+		}
+		for _, cmd := range stored {
+			if (cmd.Channel == "") == shared {
+				commands.byName[cmd.Name] = cmd
+			}
+		}
+	}
+
+	c.mu.Lock()
+	if c.generation == generation && c.generations[channel] == channelGeneration {
+		c.channels[channel] = commands
+	}
+	c.mu.Unlock()
+
+	return commands, nil
+}
+
+// Invalidate drops the commands of a channel, an empty channel drops every
+// channel since shared commands are part of all of them
+func (c *commandCache) Invalidate(channel string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if channel == "" {
+		c.generation++
+		c.channels = make(map[string]*channelCommands)
+		return
+	}
+	c.generations[channel]++
+	delete(c.channels, channel)
+}
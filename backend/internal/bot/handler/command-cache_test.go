@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"sync"
+	"testing"
+	"twitch-client/internal/db/models"
+)
+
+func TestCommandCachePrecedence(t *testing.T) {
+	store := &fakeCommandStore{commands: []models.Command{
+		{ID: 1, Channel: "", Name: "discord", Aliases: []string{"dc"}},
+		{ID: 2, Channel: "", Name: "socials", Aliases: []string{"links"}},
+		{ID: 3, Channel: "streamer", Name: "links", Aliases: []string{"socials"}},
+		{ID: 4, Channel: "streamer", Name: "rules", Aliases: []string{"r", "links"}},
+		// Hidden by the channel's own rules, along with its alias
+		{ID: 5, Channel: "", Name: "rules", Aliases: []string{"regulamin"}},
+	}}
+	cache := newCommandCache(store)
+
+	tests := []struct {
+		name string
+		want int
+	}{
+		// A channel alias beats a shared name
+		{"socials", 3},
+		// A name beats an alias of the same channel
+		{"links", 3},
+		{"r", 4},
+		{"rules", 4},
+		{"dc", 1},
+		{"discord", 1},
+	}
+
+	for _, tt := range tests {
+		cmd, ok, err := cache.Get("streamer", tt.name)
+		if err != nil || !ok || cmd.ID != tt.want {
+			t.Errorf("Get(%q) = %d, %v, %v, want %d", tt.name, cmd.ID, ok, err, tt.want)
+		}
+	}
+
+	for _, name := range []string{"missing", "regulamin"} {
+		if cmd, ok, _ := cache.Get("streamer", name); ok {
+			t.Errorf("expected no command for %q, got %d", name, cmd.ID)
+		}
+	}
+	if cmd, ok, _ := cache.Get("other", "regulamin"); !ok || cmd.ID != 5 {
+		t.Errorf("expected the shared alias in other channels, got %d, %v", cmd.ID, ok)
+	}
+	if store.loads() != 2 {
+		t.Errorf("expected one load per channel, got %d", store.loads())
+	}
+}
+
+func TestCommandCacheInvalidate(t *testing.T) {
+	store := &fakeCommandStore{commands: []models.Command{{ID: 1, Channel: "streamer", Name: "hi"}}}
+	cache := newCommandCache(store)
+
+	cache.Get("streamer", "hi")
+	cache.Get("other", "hi")
+	cache.Invalidate("streamer")
+	cache.Get("streamer", "hi")
+	cache.Get("other", "hi")
+	if store.loads() != 3 {
+		t.Errorf("expected only the invalidated channel to load again, got %d loads", store.loads())
+	}
+
+	// Shared commands are part of every channel
+	cache.Invalidate("")
+	cache.Get("streamer", "hi")
+	cache.Get("other", "hi")
+	if store.loads() != 5 {
+		t.Errorf("expected every channel to load again, got %d loads", store.loads())
+	}
+}
+
+func TestCommandCacheInvalidateDuringLoad(t *testing.T) {
+	for _, invalidated := range []string{"streamer", ""} {
+		store := &fakeCommandStore{commands: []models.Command{{ID: 1, Channel: "streamer", Name: "hi"}}}
+		cache := newCommandCache(store)
+
+		// The command changes while the old version is being read
+		store.onLoad = func() {
+			store.onLoad = nil
+			cache.Invalidate(invalidated)
+		}
+		if _, ok, err := cache.Get("streamer", "hi"); !ok || err != nil {
+			t.Fatalf("Get = %v, %v", ok, err)
+		}
+
+		cache.Get("streamer", "hi")
+		if store.loads() != 2 {
+			t.Errorf("invalidating %q: expected the stale load not to be cached, got %d loads", invalidated, store.loads())
+		}
+	}
+}
+
+// fakeCommandStore keeps commands in memory, GetChannelCommands hides a shared
+// command behind a channel command of the same name like the SQL does
+type fakeCommandStore struct {
+	commands []models.Command
+	// onLoad runs after the commands were read
+	onLoad  func()
+	queries int
+	mu      sync.Mutex
+}
+
+func (s *fakeCommandStore) GetChannelCommands(channel string) ([]models.Command, error) {
+	s.mu.Lock()
+	s.queries++
+	byName := make(map[string]models.Command)
+	for _, cmd := range s.commands {
+		if cmd.Channel != channel && cmd.Channel != "" {
+			continue
+		}
+		if current, ok := byName[cmd.Name]; !ok || current.Channel == "" {
+			byName[cmd.Name] = cmd
+		}
+	}
+	s.mu.Unlock()
+
+	if s.onLoad != nil {
+		s.onLoad()
+	}
+
+	commands := make([]models.Command, 0, len(byName))
+	for _, cmd := range byName {
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+func (s *fakeCommandStore) loads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries
+}
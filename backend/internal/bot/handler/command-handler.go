@@ -1,61 +1,132 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"twitch-client/internal/bot/template"
 	"twitch-client/internal/client"
+	"twitch-client/internal/credentials"
 	"twitch-client/internal/db"
 	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+	"twitch-client/internal/roles"
 	"twitch-client/internal/server/websocket"
+	"twitch-client/internal/service/poll"
 
 	twitchirc "github.com/gempir/go-twitch-irc/v4"
 )
 
+const (
+	// Used by !timeout when no duration is given
+	defaultTimeoutSeconds = 600
+	// Used by !permit when no duration is given
+	defaultPermitSeconds = 60
+	// How long a moderation command may take
+	moderationTimeout = 15 * time.Second
+	// How long the stream lookup of ${uptime}, ${game} and ${title} may take
+	streamLookupTimeout = 5 * time.Second
+	// How long the follower check of a command may take
+	followerLookupTimeout = 5 * time.Second
+	// How long a user found following a channel is not checked again
+	followerCacheTTL = 10 * time.Minute
+	// The user name the global cooldown of a command is kept under
+	globalCooldown = ""
+)
+
+// Moderation carries out the moderation commands, the service implements it
+type Moderation interface {
+	TimeoutUser(ctx context.Context, channel, username string, duration int, reason string, actor roles.Actor) error
+	UnbanUser(ctx context.Context, channel, username, reason string, actor roles.Actor) error
+	PermitUser(channel, username string, duration int, actor roles.Actor) error
+	UpdateChatSettings(ctx context.Context, channel string, update helix.ChatSettingsUpdate, revertAfter time.Duration, actor roles.Actor) error
+}
+
+// chatSender sends the replies of commands, the Twitch client implements it
+type chatSender interface {
+	SendMessage(channel, message string) error
+}
+
 // CustomCommand represents a custom command that can only be created on the server
 type CustomCommand struct {
-	Name            string                                   `json:"name"`
-	Description     string                                   `json:"description"`
-	Response        string                                   `json:"response"`
-	function        func([]string, twitchirc.PrivateMessage) `json:"-"` // Remove or fix the tag
-	CoolDownSeconds int                                      `json:"cooldown_seconds"`
+	Name            string                                               `json:"name"`
+	Description     string                                               `json:"description"`
+	Response        string                                               `json:"response"`
+	function        func([]string, twitchirc.PrivateMessage, roles.Role) `json:"-"` // Remove or fix the tag
+	CoolDownSeconds int                                                  `json:"cooldown_seconds"`
 }
 
 type CommandHandler struct {
 	db             *db.Database
-	twitchClient   *client.Client
+	twitchClient   chatSender
+	helix          *helix.Client
+	broadcaster    *credentials.Credentials
 	socket         *websocket.WebSocket
-	cooldowns      map[string]map[string]time.Time // command -> user -> last used
+	polls          *poll.Manager
+	commands       *commandCache
+	dashboardRoles *roleCache
+	moderation     Moderation
+	cooldowns      map[string]map[string]time.Time // channel/command -> user -> last used
+	warned         map[string]time.Time            // channel/command/user -> end of the cooldown warned about
+	followers      map[string]time.Time            // channel id/user id -> checked at
 	mu             sync.Mutex
 	prefix         string
 	customCommands map[string]CustomCommand
 }
 
-func NewCommandHandler(db *db.Database, twitchClient *client.Client, socket *websocket.WebSocket, prefix string) *CommandHandler {
+func NewCommandHandler(db *db.Database, twitchClient *client.Client, helix *helix.Client, broadcaster *credentials.Credentials, socket *websocket.WebSocket, polls *poll.Manager, prefix string) *CommandHandler {
 	ch := &CommandHandler{
 		db:             db,
 		twitchClient:   twitchClient,
+		helix:          helix,
+		broadcaster:    broadcaster,
 		socket:         socket,
+		polls:          polls,
+		commands:       newCommandCache(db),
+		dashboardRoles: newRoleCache(db),
 		cooldowns:      make(map[string]map[string]time.Time),
+		warned:         make(map[string]time.Time),
+		followers:      make(map[string]time.Time),
 		prefix:         prefix,
 		customCommands: make(map[string]CustomCommand),
 	}
 	ch.registerCustomCommands()
+
+	go func() {
+		if err := db.ListenCommandChanges(ch.commands.Invalidate); err != nil {
+			log.Printf("Failed to listen for command changes, commands are reloaded every %s: %v", commandCacheTTL, err)
+		}
+	}()
+
 	return ch
 }
 
+// InvalidateDashboardRoles makes chat commands pick up changed dashboard roles
+func (h *CommandHandler) InvalidateDashboardRoles() {
+	h.dashboardRoles.Invalidate()
+}
+
+// SetModeration enables the moderation commands
+func (h *CommandHandler) SetModeration(moderation Moderation) {
+	h.moderation = moderation
+}
+
 func (h *CommandHandler) registerCustomCommands() {
 	// Custom !tts command handler
 	h.customCommands["tts"] = CustomCommand{
 		Name:        "tts",
 		Description: "Text to speech",
 		Response:    "-",
-		function: func(args []string, msg twitchirc.PrivateMessage) {
+		function: func(args []string, msg twitchirc.PrivateMessage, role roles.Role) {
 			if len(args) == 0 {
-				msg := fmt.Sprintf("@%s, musisz podać wiadomość do wyemitowania.\nnp. !tts siema chat :D", msg.User.Name)
-				h.twitchClient.SendMessage(msg)
+				reply := fmt.Sprintf("@%s, musisz podać wiadomość do wyemitowania.\nnp. !tts siema chat :D", msg.User.Name)
+				h.twitchClient.SendMessage(msg.Channel, reply)
 				return // No message to broadcast
 			}
 
@@ -65,7 +136,7 @@ func (h *CommandHandler) registerCustomCommands() {
 			}
 
 			text := strings.Join(args, " ")
-			h.socket.BroadcastUserMessage(msg.User.Name, color, text)
+			h.socket.BroadcastUserMessage(msg.Channel, msg.User.Name, color, text)
 		},
 		CoolDownSeconds: 10,
 	}
@@ -75,53 +146,226 @@ func (h *CommandHandler) registerCustomCommands() {
 		Name:        "editcom",
 		Description: "Edit a command",
 		Response:    "-",
-		function: func(args []string, msg twitchirc.PrivateMessage) {
+		function: func(args []string, msg twitchirc.PrivateMessage, role roles.Role) {
 			if len(args) == 0 {
 				return
 			}
 
-			// check if the user is a moderator
-
-			if msg.User.Badges["moderator"] == 0 && msg.User.Badges["broadcaster"] == 0 {
-				msg := fmt.Sprintf("@%s, nie masz uprawnień do korzystania z tego polecenia", msg.User.Name)
-				h.twitchClient.SendMessage(msg)
+			if !role.AtLeast(roles.Moderator) {
+				reply := fmt.Sprintf("@%s, nie masz uprawnień do korzystania z tego polecenia", msg.User.Name)
+				h.twitchClient.SendMessage(msg.Channel, reply)
 				return
 			}
 
 			if len(args) < 2 {
-				msg := fmt.Sprintf("@%s, nie podałeś komendy lub odpowiedzi", msg.User.Name)
-				h.twitchClient.SendMessage(msg)
+				reply := fmt.Sprintf("@%s, nie podałeś komendy lub odpowiedzi", msg.User.Name)
+				h.twitchClient.SendMessage(msg.Channel, reply)
 				return
 			}
 
 			cmdName := args[0]
 			cmdResponse := strings.Join(args[1:], " ")
 
-			cmd, err := h.db.GetCommandByName(cmdName)
+			if _, err := template.Parse(cmdResponse); err != nil {
+				reply := fmt.Sprintf("@%s, odpowiedź ma błędny szablon (%v)", msg.User.Name, err)
+				h.twitchClient.SendMessage(msg.Channel, reply)
+				return
+			}
+
+			cmd, err := h.db.GetCommandByName(msg.Channel, cmdName)
 			if err != nil {
-				msg := fmt.Sprintf("@%s, komenda '%s' nie istnieje", msg.User.Name, cmdName)
-				h.twitchClient.SendMessage(msg)
+				reply := fmt.Sprintf("@%s, komenda '%s' nie istnieje", msg.User.Name, cmdName)
+				h.twitchClient.SendMessage(msg.Channel, reply)
 				return
 			}
 
 			cmd.Response = cmdResponse
 			if _, err := h.db.UpdateCommand(&cmd); err != nil {
-				msg := fmt.Sprintf("@%s, nie udało się zaktualizować komendy '%s'", msg.User.Name, cmdName)
-				h.twitchClient.SendMessage(msg)
+				reply := fmt.Sprintf("@%s, nie udało się zaktualizować komendy '%s'", msg.User.Name, cmdName)
+				h.twitchClient.SendMessage(msg.Channel, reply)
 				return
 			}
 
 			response := fmt.Sprintf("@%s, komenda '%s' została zaktualizowana", msg.User.Name, cmdName)
-			h.twitchClient.SendMessage(response)
+			h.twitchClient.SendMessage(msg.Channel, response)
 		},
 	}
 
+	// usage !vote <option number>
+	h.customCommands["vote"] = CustomCommand{
+		Name:        "vote",
+		Description: "Vote in the current poll",
+		Response:    "-",
+		function: func(args []string, msg twitchirc.PrivateMessage, role roles.Role) {
+			if len(args) == 0 {
+				return
+			}
+
+			vote, err := strconv.Atoi(args[0])
+			if err != nil {
+				reply := fmt.Sprintf("@%s, podaj numer opcji, np. !vote 1", msg.User.Name)
+				h.twitchClient.SendMessage(msg.Channel, reply)
+				return
+			}
+
+			polls := h.polls.For(msg.Channel)
+			switch err := polls.HandleVote(msg.User.Name, vote); err {
+			case nil:
+			case poll.ErrInvalidOption:
+				results := polls.Results()
+				reply := fmt.Sprintf("@%s, nie ma takiej opcji. Wybierz numer od 1 do %d", msg.User.Name, len(results.Options))
+				h.twitchClient.SendMessage(msg.Channel, reply)
+			case poll.ErrPollNotStarted, poll.ErrNotSupported:
+				// No chat poll is running, ignore the vote silently
+			default:
+				log.Printf("Failed to handle vote: %v", err)
+			}
+		},
+	}
+
+	// usage !timeout <user> [seconds] [reason]
+	h.customCommands["timeout"] = CustomCommand{
+		Name:        "timeout",
+		Description: "Time out a user",
+		Response:    "-",
+		function: func(args []string, msg twitchirc.PrivateMessage, role roles.Role) {
+			if len(args) == 0 {
+				reply := fmt.Sprintf("@%s, podaj użytkownika, np. !timeout nick 600 powód", msg.User.Name)
+				h.twitchClient.SendMessage(msg.Channel, reply)
+				return
+			}
+
+			username := strings.TrimPrefix(args[0], "@")
+			duration := defaultTimeoutSeconds
+			rest := args[1:]
+			if len(rest) > 0 {
+				if seconds, err := strconv.Atoi(rest[0]); err == nil {
+					duration = seconds
+					rest = rest[1:]
+				}
+			}
+			reason := strings.Join(rest, " ")
+
+			actor := roles.Actor{Login: msg.User.Name, Role: role}
+			done := fmt.Sprintf("%s dostał timeout na %d s", username, duration)
+			go h.moderate(msg, done, func(ctx context.Context) error {
+				return h.moderation.TimeoutUser(ctx, msg.Channel, username, duration, reason, actor)
+			})
+		},
+	}
+
+	// usage !unban <user>
+	h.customCommands["unban"] = CustomCommand{
+		Name:        "unban",
+		Description: "Unban a user or lift their timeout",
+		Response:    "-",
+		function: func(args []string, msg twitchirc.PrivateMessage, role roles.Role) {
+			if len(args) == 0 {
+				reply := fmt.Sprintf("@%s, podaj użytkownika, np. !unban nick", msg.User.Name)
+				h.twitchClient.SendMessage(msg.Channel, reply)
+				return
+			}
+
+			username := strings.TrimPrefix(args[0], "@")
+
+			actor := roles.Actor{Login: msg.User.Name, Role: role}
+			done := fmt.Sprintf("%s został odbanowany", username)
+			go h.moderate(msg, done, func(ctx context.Context) error {
+				return h.moderation.UnbanUser(ctx, msg.Channel, username, "", actor)
+			})
+		},
+	}
+
+	// usage !permit <user> [seconds]
+	h.customCommands["permit"] = CustomCommand{
+		Name:        "permit",
+		Description: "Let a user post links for a while",
+		Response:    "-",
+		function: func(args []string, msg twitchirc.PrivateMessage, role roles.Role) {
+			if len(args) == 0 {
+				reply := fmt.Sprintf("@%s, podaj użytkownika, np. !permit nick 60", msg.User.Name)
+				h.twitchClient.SendMessage(msg.Channel, reply)
+				return
+			}
+
+			username := strings.ToLower(strings.TrimPrefix(args[0], "@"))
+			duration := defaultPermitSeconds
+			if len(args) > 1 {
+				seconds, err := strconv.Atoi(args[1])
+				if err != nil {
+					reply := fmt.Sprintf("@%s, podaj czas w sekundach, np. !permit nick 60", msg.User.Name)
+					h.twitchClient.SendMessage(msg.Channel, reply)
+					return
+				}
+				duration = seconds
+			}
+
+			actor := roles.Actor{Login: msg.User.Name, Role: role}
+			done := fmt.Sprintf("%s może wysyłać linki przez %d s", username, duration)
+			go h.moderate(msg, done, func(ctx context.Context) error {
+				return h.moderation.PermitUser(msg.Channel, username, duration, actor)
+			})
+		},
+	}
+
+	// usage !slow <seconds|off> [revert after]
+	h.chatModeCommand("slow", "Switch slow mode", "!slow 10 5m", func(arg string) (helix.ChatSettingsUpdate, string, error) {
+		if arg == "off" {
+			off := false
+			return helix.ChatSettingsUpdate{SlowMode: &off}, "tryb wolny wyłączony", nil
+		}
+
+		wait, err := parseDuration(arg, time.Second)
+		if err != nil {
+			return helix.ChatSettingsUpdate{}, "", err
+		}
+
+		on, seconds := true, int(wait/time.Second)
+		update := helix.ChatSettingsUpdate{SlowMode: &on, SlowModeWaitTime: &seconds}
+		return update, fmt.Sprintf("tryb wolny włączony (%s)", formatDuration(wait)), nil
+	})
+
+	// usage !followers <minutes|off> [revert after]
+	h.chatModeCommand("followers", "Switch followers-only mode", "!followers 10m", func(arg string) (helix.ChatSettingsUpdate, string, error) {
+		if arg == "off" {
+			off := false
+			return helix.ChatSettingsUpdate{FollowerMode: &off}, "tryb tylko dla obserwujących wyłączony", nil
+		}
+
+		followed, err := parseDuration(arg, time.Minute)
+		if err != nil {
+			return helix.ChatSettingsUpdate{}, "", err
+		}
+
+		on, minutes := true, int(followed/time.Minute)
+		update := helix.ChatSettingsUpdate{FollowerMode: &on, FollowerModeDuration: &minutes}
+		return update, fmt.Sprintf("tryb tylko dla obserwujących włączony (%s)", formatDuration(followed)), nil
+	})
+
+	// usage !subonly <on|off> [revert after]
+	h.chatModeCommand("subonly", "Switch subscribers-only mode", "!subonly on 5m", func(arg string) (helix.ChatSettingsUpdate, string, error) {
+		on, err := parseSwitch(arg)
+		if err != nil {
+			return helix.ChatSettingsUpdate{}, "", err
+		}
+		return helix.ChatSettingsUpdate{SubscriberMode: &on}, "tryb tylko dla subskrybentów " + switchedText(on), nil
+	})
+
+	// usage !emoteonly <on|off> [revert after]
+	h.chatModeCommand("emoteonly", "Switch emote-only mode", "!emoteonly on 5m", func(arg string) (helix.ChatSettingsUpdate, string, error) {
+		on, err := parseSwitch(arg)
+		if err != nil {
+			return helix.ChatSettingsUpdate{}, "", err
+		}
+		return helix.ChatSettingsUpdate{EmoteMode: &on}, "tryb tylko emotki " + switchedText(on), nil
+	})
+
 	h.customCommands["commands"] = CustomCommand{
 		Name:        "commands",
 		Description: "List all available commands",
 		Response:    "-",
-		function: func(args []string, msg twitchirc.PrivateMessage) {
-			commands, err := h.GetAllCommands()
+		function: func(args []string, msg twitchirc.PrivateMessage, role roles.Role) {
+			commands, err := h.GetAllCommands(msg.Channel)
 			if err != nil {
 				log.Printf("Failed to get commands: %v", err)
 				return
@@ -130,17 +374,101 @@ func (h *CommandHandler) registerCustomCommands() {
 			var response strings.Builder
 			response.WriteString("Dostępne komendy:")
 			for i, cmd := range commands {
-				msg := fmt.Sprintf("\n\n%d. %s -> %s", i+1, cmd.Name, cmd.Description)
-				response.WriteString(msg)
+				line := fmt.Sprintf("\n\n%d. %s -> %s", i+1, cmd.Name, cmd.Description)
+				response.WriteString(line)
 			}
 
 			fmt.Println(response.String())
 
-			h.twitchClient.SendMessage(response.String())
+			h.twitchClient.SendMessage(msg.Channel, response.String())
+		},
+	}
+}
+
+// chatModeCommand registers a command that switches a chat mode, parse turns
+// the first argument into the update and describes it. An optional second
+// argument switches the mode back after that long.
+func (h *CommandHandler) chatModeCommand(name, description, example string, parse func(arg string) (helix.ChatSettingsUpdate, string, error)) {
+	h.customCommands[name] = CustomCommand{
+		Name:        name,
+		Description: description,
+		Response:    "-",
+		function: func(args []string, msg twitchirc.PrivateMessage, role roles.Role) {
+			usage := fmt.Sprintf("@%s, nieprawidłowe użycie, np. %s", msg.User.Name, example)
+			if len(args) == 0 {
+				h.twitchClient.SendMessage(msg.Channel, usage)
+				return
+			}
+
+			update, done, err := parse(strings.ToLower(args[0]))
+			if err != nil {
+				h.twitchClient.SendMessage(msg.Channel, usage)
+				return
+			}
+
+			var revertAfter time.Duration
+			if len(args) > 1 {
+				revertAfter, err = parseDuration(args[1], time.Minute)
+				if err != nil {
+					h.twitchClient.SendMessage(msg.Channel, usage)
+					return
+				}
+				done += fmt.Sprintf(", powrót za %s", formatDuration(revertAfter))
+			}
+
+			actor := roles.Actor{Login: msg.User.Name, Role: role}
+			go h.moderate(msg, done, func(ctx context.Context) error {
+				return h.moderation.UpdateChatSettings(ctx, msg.Channel, update, revertAfter, actor)
+			})
 		},
 	}
 }
 
+// userRole resolves the role of a chat user from their badges and the dashboard
+// roles. The dashboard manages the broadcaster account's channel, its roles
+// don't reach the other channels the bot joined.
+func (h *CommandHandler) userRole(msg twitchirc.PrivateMessage) roles.Role {
+	role := roles.FromBadges(msg.User.Badges)
+
+	if login, _ := h.broadcaster.Identity(); login == "" || !strings.EqualFold(login, msg.Channel) {
+		return role
+	}
+
+	dashboardRole, ok, err := h.dashboardRoles.Get(msg.User.ID)
+	if err != nil {
+		log.Printf("Failed to get dashboard role of %s: %v", msg.User.Name, err)
+	}
+	if ok {
+		role = roles.Max(role, dashboardRole)
+	}
+
+	return role
+}
+
+// moderate runs a moderation command and reports the outcome in chat, Helix
+// calls are slow so it runs outside of the IRC goroutine
+func (h *CommandHandler) moderate(msg twitchirc.PrivateMessage, done string, action func(ctx context.Context) error) {
+	if h.moderation == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), moderationTimeout)
+	defer cancel()
+
+	var reply string
+	switch err := action(ctx); {
+	case err == nil:
+		reply = fmt.Sprintf("@%s, %s", msg.User.Name, done)
+	case errors.Is(err, roles.ErrForbidden):
+		reply = fmt.Sprintf("@%s, nie masz uprawnień do korzystania z tego polecenia", msg.User.Name)
+	default:
+		log.Printf("Moderation command of %s failed: %v", msg.User.Name, err)
+		reply = fmt.Sprintf("@%s, nie udało się wykonać polecenia", msg.User.Name)
+	}
+
+	h.twitchClient.SendMessage(msg.Channel, reply)
+}
+
 func (h *CommandHandler) HandleCommand(msg twitchirc.PrivateMessage) {
 	if !strings.HasPrefix(msg.Message, h.prefix) {
 		return
@@ -154,72 +482,317 @@ func (h *CommandHandler) HandleCommand(msg twitchirc.PrivateMessage) {
 
 	fullCommand := strings.TrimPrefix(parts[0], h.prefix)
 	args := parts[1:]
+	role := h.userRole(msg)
 
 	// Check custom commands first
 	if handler, exists := h.customCommands[fullCommand]; exists {
-		handler.function(args, msg)
+		go h.record(msg, handler.Name)
+		handler.function(args, msg, role)
 		return
 	}
 
 	// Handle database commands
-	commands, err := h.db.GetAllCommands()
+	cmd, ok, err := h.commands.Get(msg.Channel, fullCommand)
 	if err != nil {
 		log.Printf("Failed to get commands: %v", err)
 		return
 	}
+	if !ok || !cmd.Enabled {
+		return
+	}
 
-	var cmd *models.Command
-	for i := range commands {
-		if commands[i].Name == fullCommand {
-			cmd = &commands[i]
-			break
-		}
+	// Moderators change counters without waiting for the cooldown
+	if change, ok := h.counterUpdate(cmd, role, args); ok {
+		go h.record(msg, cmd.Name)
+		go h.updateCounter(cmd, msg, change)
+		return
+	}
+
+	// The permission check may look up followers and rendering the stream,
+	// keep them off the IRC goroutine
+	go h.run(cmd, msg, role, args)
+}
+
+// run uses a command unless the user isn't allowed to or it is cooling down
+func (h *CommandHandler) run(cmd models.Command, msg twitchirc.PrivateMessage, role roles.Role, args []string) {
+	if !h.permitted(cmd, msg, role) {
+		return
 	}
 
-	if cmd == nil || !cmd.Enabled {
+	if !h.cooledDown(cmd, msg) {
 		return
 	}
 
-	// Check cooldown
+	h.record(msg, cmd.Name)
+	h.respond(cmd, msg, args)
+}
+
+// cooledDown checks the cooldown of a command and starts a new one when the
+// command may be used. Commands cool down separately in every channel.
+func (h *CommandHandler) cooledDown(cmd models.Command, msg twitchirc.PrivateMessage) bool {
+	if cmd.CooldownSeconds <= 0 || msg.User.Badges["broadcaster"] > 0 {
+		return true
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if cmd.CooldownSeconds > 0 {
-		userLastUsed, exists := h.cooldowns[cmd.Name][msg.User.Name]
-		if exists {
-			cooldownEnd := userLastUsed.Add(time.Duration(cmd.CooldownSeconds) * time.Second)
-			if time.Now().Before(cooldownEnd) && msg.User.Badges["broadcaster"] != 1 {
-				// get the time left on the cooldown
-				remaining := cooldownEnd.Sub(time.Now())
-				msg := fmt.Sprintf("@%s, komenda '%s' jest na cooldown'ie. Pozostało %s", msg.User.Name, cmd.Name, remaining)
-				h.twitchClient.SendMessage(msg)
-				return
-			}
+	cooldownKey := msg.Channel + "/" + cmd.Name
+	cooldown := time.Duration(cmd.CooldownSeconds) * time.Second
+
+	var users []string
+	switch cmd.CooldownMode {
+	case models.CooldownGlobal:
+		users = []string{globalCooldown}
+	case models.CooldownBoth:
+		users = []string{globalCooldown, msg.User.Name}
+	default:
+		users = []string{msg.User.Name}
+	}
+
+	var cooldownEnd time.Time
+	for _, user := range users {
+		if lastUsed, exists := h.cooldowns[cooldownKey][user]; exists && lastUsed.Add(cooldown).After(cooldownEnd) {
+			cooldownEnd = lastUsed.Add(cooldown)
 		}
 	}
 
-	// Process command response
-	response := h.replacePlaceholders(cmd.Response, msg.User.Name, args)
-	if msg.Channel != "" {
-		h.twitchClient.SendMessage(response)
+	if time.Now().Before(cooldownEnd) {
+		// Warn every user once per cooldown, repeated warnings are spam
+		warningKey := cooldownKey + "/" + msg.User.Name
+		if cmd.CooldownWarning && !h.warned[warningKey].Equal(cooldownEnd) {
+			h.warned[warningKey] = cooldownEnd
+			remaining := time.Until(cooldownEnd).Round(time.Second)
+			reply := fmt.Sprintf("@%s, komenda '%s' jest na cooldown'ie. Pozostało %s", msg.User.Name, cmd.Name, formatDuration(remaining))
+			h.twitchClient.SendMessage(msg.Channel, reply)
+		}
+		return false
+	}
+
+	if h.cooldowns[cooldownKey] == nil {
+		h.cooldowns[cooldownKey] = make(map[string]time.Time)
+	}
+	for _, user := range users {
+		h.cooldowns[cooldownKey][user] = time.Now()
+	}
+	delete(h.warned, cooldownKey+"/"+msg.User.Name)
+
+	return true
+}
+
+// permitted tells whether the user has the permission level of the command
+func (h *CommandHandler) permitted(cmd models.Command, msg twitchirc.PrivateMessage, role roles.Role) bool {
+	required := slices.Index(models.PermissionLevels, cmd.PermissionLevel)
+	if required < 0 {
+		log.Printf("Unknown permission level %q of command %s", cmd.PermissionLevel, cmd.Name)
+		return false
+	}
+
+	if permissionLevel(msg.User, role) >= required {
+		return true
+	}
+
+	return cmd.PermissionLevel == models.PermissionFollower && h.isFollower(msg)
+}
+
+// permissionLevel is the index of the highest level the badges and the role
+// of a user grant, following isn't visible in badges
+func permissionLevel(user twitchirc.User, role roles.Role) int {
+	level := models.PermissionEveryone
+	switch {
+	case user.Badges["broadcaster"] > 0:
+		level = models.PermissionBroadcaster
+	case role.AtLeast(roles.Moderator):
+		level = models.PermissionModerator
+	case hasBadge(user, "vip"):
+		level = models.PermissionVIP
+	case hasBadge(user, "subscriber"), hasBadge(user, "founder"):
+		level = models.PermissionSubscriber
+	}
+	return slices.Index(models.PermissionLevels, level)
+}
+
+// isFollower looks up whether the user follows the channel, followers are
+// remembered for a while
+func (h *CommandHandler) isFollower(msg twitchirc.PrivateMessage) bool {
+	key := msg.RoomID + "/" + msg.User.ID
+
+	h.mu.Lock()
+	checked, ok := h.followers[key]
+	h.mu.Unlock()
+	if ok && time.Since(checked) < followerCacheTTL {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), followerLookupTimeout)
+	defer cancel()
+
+	follows, err := h.helix.IsFollower(ctx, msg.RoomID, msg.User.ID)
+	if err != nil {
+		log.Printf("Failed to check whether %s follows %s: %v", msg.User.Name, msg.Channel, err)
+		return false
+	}
+
+	if follows {
+		h.mu.Lock()
+		h.followers[key] = time.Now()
+		h.mu.Unlock()
+	}
+
+	return follows
+}
+
+// hasBadge tells whether the user wears a badge, some badges like subscriber can be version 0
+func hasBadge(user twitchirc.User, badge string) bool {
+	_, ok := user.Badges[badge]
+	return ok
+}
+
+// respond renders the response template of a command and sends it
+func (h *CommandHandler) respond(cmd models.Command, msg twitchirc.PrivateMessage, args []string) {
+	h.send(cmd, msg, template.Data{
+		User:      msg.User.Name,
+		Channel:   msg.Channel,
+		Args:      args,
+		Stream:    func() (template.Stream, error) { return h.stream(msg.Channel) },
+		Count:     func() (int, error) { return h.db.GetCommandCount(cmd.ID) },
+		Increment: func() (int, error) { return h.db.AddCommandCount(cmd.ID, 1) },
+	})
+}
+
+func (h *CommandHandler) send(cmd models.Command, msg twitchirc.PrivateMessage, data template.Data) {
+	tmpl, err := template.Parse(cmd.Response)
+	if err != nil {
+		log.Printf("Invalid response of command %s: %v", cmd.Name, err)
+		h.twitchClient.SendMessage(msg.Channel, cmd.Response)
+		return
+	}
+
+	response, err := tmpl.Execute(data)
+	if err != nil {
+		log.Printf("Failed to render command %s: %v", cmd.Name, err)
+	}
+
+	if response != "" {
+		h.twitchClient.SendMessage(msg.Channel, response)
 	}
+}
 
-	// Update cooldown
-	if cmd.CooldownSeconds > 0 {
-		if h.cooldowns[cmd.Name] == nil {
-			h.cooldowns[cmd.Name] = make(map[string]time.Time)
+// counterChange is a counter subcommand, it either sets the counter or moves it by delta
+type counterChange struct {
+	set   bool
+	value int
+	delta int
+}
+
+// counterUpdate reads the counter subcommands moderators may use on commands
+// showing ${count}: +N, -N, reset and set N. Anything else is a normal use.
+func (h *CommandHandler) counterUpdate(cmd models.Command, role roles.Role, args []string) (counterChange, bool) {
+	if len(args) == 0 {
+		return counterChange{}, false
+	}
+
+	tmpl, err := template.Parse(cmd.Response)
+	if err != nil || !tmpl.IsCounter() {
+		return counterChange{}, false
+	}
+
+	var change counterChange
+	switch op := strings.ToLower(args[0]); {
+	case op == "reset":
+		change = counterChange{set: true}
+	case op == "set" && len(args) > 1:
+		count, err := strconv.Atoi(args[1])
+		if err != nil {
+			return counterChange{}, false
 		}
-		h.cooldowns[cmd.Name][msg.User.Name] = time.Now()
+		change = counterChange{set: true, value: count}
+	case strings.HasPrefix(op, "+") || strings.HasPrefix(op, "-"):
+		delta, err := strconv.Atoi(op)
+		if err != nil || delta == 0 {
+			return counterChange{}, false
+		}
+		change = counterChange{delta: delta}
+	default:
+		return counterChange{}, false
+	}
+
+	if !role.AtLeast(roles.Moderator) {
+		return counterChange{}, false
 	}
+
+	return change, true
 }
 
-func (h *CommandHandler) replacePlaceholders(response, username string, args []string) string {
-	replaced := strings.ReplaceAll(response, "${user}", username)
-	return strings.ReplaceAll(replaced, "${args}", strings.Join(args, " "))
+// updateCounter changes the counter of a command and shows the response with the new value
+func (h *CommandHandler) updateCounter(cmd models.Command, msg twitchirc.PrivateMessage, change counterChange) {
+	count := change.value
+	var err error
+	if change.set {
+		err = h.db.SetCommandCount(cmd.ID, count)
+	} else {
+		count, err = h.db.AddCommandCount(cmd.ID, change.delta)
+	}
+	if err != nil {
+		log.Printf("Failed to update counter of command %s: %v", cmd.Name, err)
+		reply := fmt.Sprintf("@%s, nie udało się zmienić licznika", msg.User.Name)
+		h.twitchClient.SendMessage(msg.Channel, reply)
+		return
+	}
+
+	current := func() (int, error) { return count, nil }
+	h.send(cmd, msg, template.Data{
+		User:      msg.User.Name,
+		Channel:   msg.Channel,
+		Stream:    func() (template.Stream, error) { return h.stream(msg.Channel) },
+		Count:     current,
+		Increment: current,
+	})
+}
+
+// record stores a use of a command for the usage statistics
+func (h *CommandHandler) record(msg twitchirc.PrivateMessage, command string) {
+	invocation := models.CommandInvocation{
+		Channel:  msg.Channel,
+		Command:  command,
+		UserID:   msg.User.ID,
+		Username: msg.User.Name,
+	}
+	if err := h.db.RecordCommandInvocation(invocation); err != nil {
+		log.Printf("Failed to record use of command %s: %v", command, err)
+	}
+}
+
+// stream looks up what ${uptime}, ${game} and ${title} show, the channel
+// information fills in the game and title while the stream is offline
+func (h *CommandHandler) stream(channel string) (template.Stream, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), streamLookupTimeout)
+	defer cancel()
+
+	stream, err := h.helix.GetStream(ctx, channel)
+	if err != nil {
+		return template.Stream{}, err
+	}
+	if stream != nil {
+		return template.Stream{Live: true, StartedAt: stream.StartedAt, Game: stream.GameName, Title: stream.Title}, nil
+	}
+
+	broadcasterID, err := h.helix.GetUserID(ctx, channel)
+	if err != nil {
+		return template.Stream{}, err
+	}
+
+	info, err := h.helix.GetChannelInformation(ctx, broadcasterID)
+	if err != nil {
+		return template.Stream{}, err
+	}
+
+	return template.Stream{Game: info.GameName, Title: info.Title}, nil
 }
 
-func (h *CommandHandler) GetAllCommands() ([]models.Command, error) {
-	cmds, err := h.db.GetAllCommands()
+// GetAllCommands returns the commands available in a channel, including the custom ones
+func (h *CommandHandler) GetAllCommands(channel string) ([]models.Command, error) {
+	cmds, err := h.db.GetChannelCommands(channel)
 	if err != nil {
 		return nil, err
 	}
@@ -242,3 +815,51 @@ func (h *CommandHandler) customCommandsToModels() []models.Command {
 	}
 	return cmds
 }
+
+// parseDuration reads durations like "30", "10m" or "2h", a bare number is in units of unit
+func parseDuration(value string, unit time.Duration) (time.Duration, error) {
+	units := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour}
+
+	if multiplier, ok := units[value[len(value)-1]]; ok && len(value) > 1 {
+		unit = multiplier
+		value = value[:len(value)-1]
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+// formatDuration writes a duration the way chat reads it, like "5 min"
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d d", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%d h", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%d min", d/time.Minute)
+	default:
+		return fmt.Sprintf("%d s", d/time.Second)
+	}
+}
+
+func parseSwitch(value string) (bool, error) {
+	switch value {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected on or off, got %s", value)
+}
+
+func switchedText(on bool) string {
+	if on {
+		return "włączony"
+	}
+	return "wyłączony"
+}
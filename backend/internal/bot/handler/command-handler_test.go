@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"testing"
+	"time"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/roles"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+// fakeSender keeps the messages sent to chat
+type fakeSender struct {
+	sent []string
+}
+
+func (s *fakeSender) SendMessage(channel, message string) error {
+	s.sent = append(s.sent, message)
+	return nil
+}
+
+func newTestHandler() (*CommandHandler, *fakeSender) {
+	sender := &fakeSender{}
+	return &CommandHandler{
+		twitchClient: sender,
+		cooldowns:    make(map[string]map[string]time.Time),
+		warned:       make(map[string]time.Time),
+	}, sender
+}
+
+func chatMessage(user string, badges map[string]int) twitchirc.PrivateMessage {
+	return twitchirc.PrivateMessage{
+		Channel: "streamer",
+		User:    twitchirc.User{ID: user, Name: user, Badges: badges},
+	}
+}
+
+func TestCooledDown(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+		// Who uses the command in turn and whether they may
+		uses []string
+		want []bool
+	}{
+		{"per user", models.CooldownPerUser, []string{"alice", "alice", "bob"}, []bool{true, false, true}},
+		{"global", models.CooldownGlobal, []string{"alice", "bob", "alice"}, []bool{true, false, false}},
+		{"both", models.CooldownBoth, []string{"alice", "bob", "alice"}, []bool{true, false, false}},
+		{"unknown mode is per user", "", []string{"alice", "bob", "bob"}, []bool{true, true, false}},
+		{"broadcaster skips the cooldown", models.CooldownGlobal, []string{"alice", "streamer"}, []bool{true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newTestHandler()
+			cmd := models.Command{Name: "discord", CooldownSeconds: 30, CooldownMode: tt.mode}
+
+			for i, user := range tt.uses {
+				var badges map[string]int
+				if user == "streamer" {
+					badges = map[string]int{"broadcaster": 1}
+				}
+				if got := h.cooledDown(cmd, chatMessage(user, badges)); got != tt.want[i] {
+					t.Errorf("use %d by %s = %v, want %v", i+1, user, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCooledDownExpires(t *testing.T) {
+	h, _ := newTestHandler()
+	cmd := models.Command{Name: "discord", CooldownSeconds: 30, CooldownMode: models.CooldownBoth}
+
+	h.cooledDown(cmd, chatMessage("alice", nil))
+	// Both cooldowns of the use ran out
+	for user := range h.cooldowns["streamer/discord"] {
+		h.cooldowns["streamer/discord"][user] = time.Now().Add(-31 * time.Second)
+	}
+	if !h.cooledDown(cmd, chatMessage("alice", nil)) {
+		t.Error("expected the command to be usable after the cooldown")
+	}
+
+	// The global cooldown ran out, the one of the user didn't
+	h.cooldowns["streamer/discord"][globalCooldown] = time.Now().Add(-31 * time.Second)
+	if h.cooledDown(cmd, chatMessage("alice", nil)) {
+		t.Error("expected the cooldown of the user to still apply")
+	}
+	if !h.cooledDown(cmd, chatMessage("bob", nil)) {
+		t.Error("expected other users to use the command")
+	}
+
+	if !h.cooledDown(models.Command{Name: "free"}, chatMessage("alice", nil)) {
+		t.Error("expected a command without a cooldown to be usable")
+	}
+}
+
+func TestCooledDownWarnsOnce(t *testing.T) {
+	h, sender := newTestHandler()
+	cmd := models.Command{Name: "discord", CooldownSeconds: 30, CooldownMode: models.CooldownGlobal, CooldownWarning: true}
+
+	h.cooledDown(cmd, chatMessage("alice", nil))
+	h.cooledDown(cmd, chatMessage("bob", nil))
+	h.cooledDown(cmd, chatMessage("bob", nil))
+	h.cooledDown(cmd, chatMessage("alice", nil))
+	if len(sender.sent) != 2 {
+		t.Fatalf("expected one warning per user, got %q", sender.sent)
+	}
+
+	// A new cooldown is warned about again
+	h.cooldowns["streamer/discord"][globalCooldown] = time.Now().Add(-31 * time.Second)
+	h.cooledDown(cmd, chatMessage("alice", nil))
+	h.cooledDown(cmd, chatMessage("bob", nil))
+	if len(sender.sent) != 3 {
+		t.Errorf("expected a warning for the new cooldown, got %q", sender.sent)
+	}
+
+	cmd.CooldownWarning = false
+	h.cooledDown(cmd, chatMessage("carol", nil))
+	if len(sender.sent) != 3 {
+		t.Errorf("expected silent cooldowns without warnings, got %q", sender.sent)
+	}
+}
+
+func TestCounterUpdate(t *testing.T) {
+	counter := models.Command{Name: "deaths", Response: "Zgony: ${count}"}
+	tests := []struct {
+		name string
+		cmd  models.Command
+		role roles.Role
+		args []string
+		want counterChange
+		ok   bool
+	}{
+		{"add", counter, roles.Moderator, []string{"+3"}, counterChange{delta: 3}, true},
+		{"subtract", counter, roles.Moderator, []string{"-2"}, counterChange{delta: -2}, true},
+		{"reset", counter, roles.Editor, []string{"RESET"}, counterChange{set: true}, true},
+		{"set", counter, roles.Owner, []string{"set", "42"}, counterChange{set: true, value: 42}, true},
+		{"zero delta", counter, roles.Moderator, []string{"+0"}, counterChange{}, false},
+		{"invalid delta", counter, roles.Moderator, []string{"+abc"}, counterChange{}, false},
+		{"set without a value", counter, roles.Moderator, []string{"set"}, counterChange{}, false},
+		{"set to an invalid value", counter, roles.Moderator, []string{"set", "dużo"}, counterChange{}, false},
+		{"other argument", counter, roles.Moderator, []string{"@viewer"}, counterChange{}, false},
+		{"no arguments", counter, roles.Moderator, nil, counterChange{}, false},
+		{"viewer role", counter, roles.Viewer, []string{"+1"}, counterChange{}, false},
+		{"no role", counter, "", []string{"reset"}, counterChange{}, false},
+		{"not a counter", models.Command{Name: "discord", Response: "example.gg"}, roles.Moderator, []string{"+1"}, counterChange{}, false},
+	}
+
+	h, _ := newTestHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := h.counterUpdate(tt.cmd, tt.role, tt.args)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("counterUpdate(%q) = %+v, %v, want %+v, %v", tt.args, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
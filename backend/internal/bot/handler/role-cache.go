@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/roles"
+)
+
+// Dashboard roles are loaded again after this long in case another backend instance changed them
+const roleCacheTTL = 10 * time.Minute
+
+type dashboardUserStore interface {
+	GetDashboardUsers() ([]models.DashboardUser, error)
+}
+
+// roleCache keeps the dashboard roles by user ID, so chat commands don't hit
+// the database. Changes made through the dashboard invalidate it.
+type roleCache struct {
+	store    dashboardUserStore
+	roles    map[string]roles.Role
+	loadedAt time.Time
+	// Bumped by Invalidate, so a load that raced with it doesn't store what it read before
+	generation uint64
+	mu         sync.RWMutex
+}
+
+func newRoleCache(store dashboardUserStore) *roleCache {
+	return &roleCache{store: store}
+}
+
+// Get returns the dashboard role of a user, if they have one
+func (c *roleCache) Get(userID string) (roles.Role, bool, error) {
+	c.mu.RLock()
+	current, loadedAt := c.roles, c.loadedAt
+	c.mu.RUnlock()
+
+	if current == nil || time.Since(loadedAt) > roleCacheTTL {
+		var err error
+		if current, err = c.load(); err != nil {
+			return "", false, err
+		}
+	}
+
+	role, ok := current[userID]
+	return role, ok, nil
+}
+
+func (c *roleCache) load() (map[string]roles.Role, error) {
+	c.mu.RLock()
+	generation := c.generation
+	c.mu.RUnlock()
+
+	users, err := c.store.GetDashboardUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard users: %w", err)
+	}
+
+	loaded := make(map[string]roles.Role, len(users))
+	for _, user := range users {
+		loaded[user.UserID] = user.Role
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.roles = loaded
+		c.loadedAt = time.Now()
+	}
+	c.mu.Unlock()
+
+	return loaded, nil
+}
+
+// Invalidate drops the roles, they are loaded again on the next lookup
+func (c *roleCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.roles = nil
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"testing"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/roles"
+)
+
+// fakeDashboardUsers is an in-memory dashboardUserStore
+type fakeDashboardUsers struct {
+	users []models.DashboardUser
+	loads int
+	// onLoad runs after the users were read
+	onLoad func()
+}
+
+func (s *fakeDashboardUsers) GetDashboardUsers() ([]models.DashboardUser, error) {
+	s.loads++
+	users := s.users
+	if s.onLoad != nil {
+		s.onLoad()
+	}
+	return users, nil
+}
+
+func TestRoleCache(t *testing.T) {
+	store := &fakeDashboardUsers{users: []models.DashboardUser{{UserID: "1", Role: roles.Moderator}}}
+	cache := newRoleCache(store)
+
+	if role, ok, err := cache.Get("1"); err != nil || !ok || role != roles.Moderator {
+		t.Fatalf("Get(1) = %q, %v, %v", role, ok, err)
+	}
+	if _, ok, _ := cache.Get("2"); ok {
+		t.Error("expected no role for a user without one")
+	}
+	if store.loads != 1 {
+		t.Errorf("expected one load, got %d", store.loads)
+	}
+
+	// A role given on the dashboard shows up after the invalidation
+	store.users = append(store.users, models.DashboardUser{UserID: "2", Role: roles.Editor})
+	cache.Invalidate()
+	if role, ok, _ := cache.Get("2"); !ok || role != roles.Editor || store.loads != 2 {
+		t.Errorf("expected the new role after a reload, got %q, %v after %d loads", role, ok, store.loads)
+	}
+}
+
+func TestRoleCacheInvalidateDuringLoad(t *testing.T) {
+	store := &fakeDashboardUsers{users: []models.DashboardUser{{UserID: "1", Role: roles.Moderator}}}
+	cache := newRoleCache(store)
+
+	// The role is removed while the old roles are being read
+	store.onLoad = func() {
+		store.onLoad = nil
+		store.users = nil
+		cache.Invalidate()
+	}
+	cache.Get("1")
+
+	if _, ok, _ := cache.Get("1"); ok || store.loads != 2 {
+		t.Errorf("expected the stale load not to be cached, got %v after %d loads", ok, store.loads)
+	}
+}
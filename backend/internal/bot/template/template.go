@@ -0,0 +1,241 @@
+// Package template renders the responses of chat commands. Variables are
+// written as ${name}, positional arguments as ${1} or ${1|default} and
+// ${random.pick a|b|c} picks one of the options. ${count} shows the counter
+// of the command, ${count+1} raises it first.
+package template
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// Variables that take no argument
+var variables = map[string]bool{
+	"user":    true,
+	"touser":  true,
+	"args":    true,
+	"channel": true,
+	"uptime":  true,
+	"game":    true,
+	"title":   true,
+	"count":   true,
+	"count+1": true,
+}
+
+// Stream is what the stream variables are filled from
+type Stream struct {
+	Live      bool
+	StartedAt time.Time
+	Game      string
+	Title     string
+}
+
+// Data is what a response is rendered with. The lookups are slow, they are
+// called at most once and only if the template refers to them.
+type Data struct {
+	User      string
+	Channel   string
+	Args      []string
+	Stream    func() (Stream, error)
+	Count     func() (int, error)
+	Increment func() (int, error)
+}
+
+type node struct {
+	// text is written as is when variable is empty
+	text     string
+	variable string
+	// position of the argument for ${N}, starting at 1
+	position int
+	fallback string
+	options  []string
+}
+
+type Template struct {
+	nodes []node
+}
+
+// Parse checks a response and prepares it for rendering
+func Parse(response string) (*Template, error) {
+	t := &Template{}
+
+	for rest := response; rest != ""; {
+		start := strings.Index(rest, "${")
+		if start < 0 {
+			t.nodes = append(t.nodes, node{text: rest})
+			break
+		}
+		if start > 0 {
+			t.nodes = append(t.nodes, node{text: rest[:start]})
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unclosed %s", ErrInvalidTemplate, rest[start:])
+		}
+
+		n, err := parseVariable(rest[start+2 : start+end])
+		if err != nil {
+			return nil, err
+		}
+		t.nodes = append(t.nodes, n)
+
+		rest = rest[start+end+1:]
+	}
+
+	return t, nil
+}
+
+func parseVariable(expr string) (node, error) {
+	expr = strings.TrimSpace(expr)
+
+	if expr != "" && expr[0] >= '0' && expr[0] <= '9' {
+		position, fallback, _ := strings.Cut(expr, "|")
+		n, err := strconv.Atoi(strings.TrimSpace(position))
+		if err != nil || n < 1 {
+			return node{}, fmt.Errorf("%w: ${%s} is not an argument position", ErrInvalidTemplate, expr)
+		}
+		return node{variable: "arg", position: n, fallback: fallback}, nil
+	}
+
+	name, arg, _ := strings.Cut(expr, " ")
+	if name == "random.pick" {
+		var options []string
+		for _, option := range strings.Split(arg, "|") {
+			if option = strings.TrimSpace(option); option != "" {
+				options = append(options, option)
+			}
+		}
+		if len(options) == 0 {
+			return node{}, fmt.Errorf("%w: ${random.pick} needs options like ${random.pick a|b|c}", ErrInvalidTemplate)
+		}
+		return node{variable: name, options: options}, nil
+	}
+
+	if !variables[name] {
+		return node{}, fmt.Errorf("%w: unknown variable ${%s}", ErrInvalidTemplate, expr)
+	}
+	if strings.TrimSpace(arg) != "" {
+		return node{}, fmt.Errorf("%w: ${%s} takes no argument", ErrInvalidTemplate, name)
+	}
+
+	return node{variable: name}, nil
+}
+
+// Execute renders the template. Variables whose lookup failed are left empty
+// and the first failure is returned along with the response.
+func (t *Template) Execute(data Data) (string, error) {
+	var out strings.Builder
+	var firstErr error
+
+	var stream *Stream
+	lookupStream := func() Stream {
+		if stream == nil {
+			stream = &Stream{}
+			if data.Stream != nil {
+				s, err := data.Stream()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("failed to look up stream: %w", err)
+				}
+				stream = &s
+			}
+		}
+		return *stream
+	}
+
+	// A template that raises the counter shows the raised value everywhere
+	countLookup := data.Count
+	if t.Uses("count+1") {
+		countLookup = data.Increment
+	}
+
+	var count *int
+	lookupCount := func() int {
+		if count == nil {
+			count = new(int)
+			if countLookup != nil {
+				c, err := countLookup()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("failed to look up count: %w", err)
+				}
+				*count = c
+			}
+		}
+		return *count
+	}
+
+	for _, n := range t.nodes {
+		switch n.variable {
+		case "":
+			out.WriteString(n.text)
+		case "user":
+			out.WriteString(data.User)
+		case "touser":
+			out.WriteString(toUser(data))
+		case "args":
+			out.WriteString(strings.Join(data.Args, " "))
+		case "arg":
+			if n.position <= len(data.Args) {
+				out.WriteString(data.Args[n.position-1])
+			} else {
+				out.WriteString(n.fallback)
+			}
+		case "channel":
+			out.WriteString(data.Channel)
+		case "uptime":
+			out.WriteString(formatUptime(lookupStream()))
+		case "game":
+			out.WriteString(lookupStream().Game)
+		case "title":
+			out.WriteString(lookupStream().Title)
+		case "count", "count+1":
+			out.WriteString(strconv.Itoa(lookupCount()))
+		case "random.pick":
+			out.WriteString(n.options[rand.Intn(len(n.options))])
+		}
+	}
+
+	return strings.TrimSpace(out.String()), firstErr
+}
+
+// Uses tells whether the template refers to the variable
+func (t *Template) Uses(variable string) bool {
+	for _, n := range t.nodes {
+		if n.variable == variable {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCounter tells whether the template shows the counter of its command
+func (t *Template) IsCounter() bool {
+	return t.Uses("count") || t.Uses("count+1")
+}
+
+// toUser is the user named in the first argument, or the caller without one
+func toUser(data Data) string {
+	if len(data.Args) > 0 {
+		return strings.TrimPrefix(data.Args[0], "@")
+	}
+	return data.User
+}
+
+func formatUptime(stream Stream) string {
+	if !stream.Live {
+		return "offline"
+	}
+
+	uptime := time.Since(stream.StartedAt)
+	hours, minutes := int(uptime.Hours()), int(uptime.Minutes())%60
+	if hours > 0 {
+		return fmt.Sprintf("%d h %d min", hours, minutes)
+	}
+	return fmt.Sprintf("%d min", minutes)
+}
@@ -0,0 +1,164 @@
+package template
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"hello ${user",
+		"${unknown}",
+		"${0}",
+		"${1x}",
+		"${random.pick}",
+		"${random.pick | |}",
+		"${user extra}",
+		"${count +1}",
+	}
+
+	for _, response := range tests {
+		if _, err := Parse(response); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidTemplate", response, err)
+		}
+	}
+}
+
+func TestExecute(t *testing.T) {
+	data := Data{
+		User:    "viewer",
+		Channel: "streamer",
+		Args:    []string{"@friend", "second"},
+		Stream: func() (Stream, error) {
+			return Stream{Live: true, StartedAt: time.Now().Add(-90*time.Minute - 30*time.Second), Game: "Chess", Title: "Ranked"}, nil
+		},
+	}
+
+	tests := []struct {
+		response string
+		data     Data
+		want     string
+	}{
+		{"plain text", data, "plain text"},
+		{"hi ${user} from ${channel}", data, "hi viewer from streamer"},
+		{"${touser} says hi", data, "friend says hi"},
+		{"${touser} says hi", Data{User: "viewer"}, "viewer says hi"},
+		{"you said: ${args}", data, "you said: @friend second"},
+		{"${2} then ${1}", data, "second then @friend"},
+		{"${3|nobody}", data, "nobody"},
+		{"${ 3 | nobody }", data, "nobody"},
+		{"${3}", data, ""},
+		{"${game}: ${title}, live for ${uptime}", data, "Chess: Ranked, live for 1 h 30 min"},
+		{"live for ${uptime}", Data{Stream: func() (Stream, error) { return Stream{}, nil }}, "live for offline"},
+		{"  ${user}  ", data, "viewer"},
+		{"costs $5 {not a variable}", data, "costs $5 {not a variable}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.response, func(t *testing.T) {
+			tmpl, err := Parse(tt.response)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := tmpl.Execute(tt.data)
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteLooksUpOnce(t *testing.T) {
+	streams := 0
+	data := Data{Stream: func() (Stream, error) {
+		streams++
+		return Stream{Game: "Chess", Title: "Ranked"}, nil
+	}}
+
+	tmpl, err := Parse("${game} ${title} ${game}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := tmpl.Execute(data); got != "Chess Ranked Chess" || streams != 1 {
+		t.Errorf("got %q after %d lookups, want one lookup", got, streams)
+	}
+
+	// Templates without stream variables don't look it up at all
+	tmpl, _ = Parse("hi ${user}")
+	tmpl.Execute(data)
+	if streams != 1 {
+		t.Errorf("expected no lookup, got %d", streams-1)
+	}
+}
+
+func TestExecuteCount(t *testing.T) {
+	counter := 41
+	var calls []string
+	data := Data{
+		Count: func() (int, error) {
+			calls = append(calls, "count")
+			return counter, nil
+		},
+		Increment: func() (int, error) {
+			calls = append(calls, "increment")
+			counter++
+			return counter, nil
+		},
+	}
+
+	tmpl, _ := Parse("shown ${count} times")
+	if got, _ := tmpl.Execute(data); got != "shown 41 times" {
+		t.Errorf("got %q", got)
+	}
+
+	// ${count+1} raises the counter once and every ${count} shows the raised value
+	tmpl, _ = Parse("${count+1}, yes ${count}")
+	if got, _ := tmpl.Execute(data); got != "42, yes 42" {
+		t.Errorf("got %q", got)
+	}
+	if !slices.Equal(calls, []string{"count", "increment"}) {
+		t.Errorf("unexpected lookups %v", calls)
+	}
+	if !tmpl.IsCounter() {
+		t.Error("expected a counter template")
+	}
+}
+
+func TestExecuteLookupError(t *testing.T) {
+	lookupErr := errors.New("helix down")
+	data := Data{
+		User:   "viewer",
+		Stream: func() (Stream, error) { return Stream{}, lookupErr },
+		Count:  func() (int, error) { return 0, errors.New("db down") },
+	}
+
+	tmpl, _ := Parse("${user} plays ${game}, ${count}")
+	got, err := tmpl.Execute(data)
+	if !errors.Is(err, lookupErr) {
+		t.Errorf("expected the first lookup error, got %v", err)
+	}
+	if got != "viewer plays , 0" {
+		t.Errorf("expected failed lookups to be left empty, got %q", got)
+	}
+}
+
+func TestRandomPick(t *testing.T) {
+	tmpl, err := Parse("${random.pick heads | tails |}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		got, _ := tmpl.Execute(Data{})
+		seen[got] = true
+	}
+	if len(seen) != 2 || !seen["heads"] || !seen["tails"] {
+		t.Errorf("expected both options, got %v", seen)
+	}
+}
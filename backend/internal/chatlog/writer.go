@@ -0,0 +1,160 @@
+package chatlog
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"twitch-client/internal/db/models"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+const (
+	// Messages waiting to be written, when the queue is full new messages are dropped
+	queueSize = 4096
+	// Messages are written once this many are waiting
+	batchSize = 200
+	// or at least this often
+	flushInterval = 2 * time.Second
+)
+
+// Store saves a batch of chat messages in one transaction, *db.Database implements it
+type Store interface {
+	SaveChatMessages(messages []models.ChatMessage) error
+}
+
+// Writer stores chat messages in Postgres in batches, Write never blocks the IRC callback
+type Writer struct {
+	db      Store
+	queue   chan models.ChatMessage
+	done    chan struct{}
+	dropped int
+	closed  bool
+	mu      sync.Mutex
+}
+
+func NewWriter(db Store) *Writer {
+	return &Writer{
+		db:    db,
+		queue: make(chan models.ChatMessage, queueSize),
+		done:  make(chan struct{}),
+	}
+}
+
+// Write queues a chat message to be stored
+func (w *Writer) Write(message twitchirc.PrivateMessage) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	select {
+	case w.queue <- toModel(message):
+	default:
+		w.dropped++
+	}
+}
+
+// Run writes the queued messages until Close is called
+func (w *Writer) Run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.ChatMessage, 0, batchSize)
+	for {
+		select {
+		case message, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+
+			batch = append(batch, message)
+			if len(batch) >= batchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// Close writes the remaining messages and stops the writer, later messages are ignored
+func (w *Writer) Close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+}
+
+func (w *Writer) flush(batch []models.ChatMessage) {
+	w.mu.Lock()
+	dropped := w.dropped
+	w.dropped = 0
+	w.mu.Unlock()
+
+	if dropped > 0 {
+		log.Printf("Chat log queue was full, dropped %d messages", dropped)
+	}
+
+	if len(batch) == 0 {
+		return
+	}
+
+	err := w.db.SaveChatMessages(batch)
+	if err == nil {
+		return
+	}
+	if len(batch) == 1 {
+		log.Printf("Failed to save chat message %s: %v", batch[0].MessageID, err)
+		return
+	}
+	log.Printf("Failed to save %d chat messages, saving them one by one: %v", len(batch), err)
+
+	// One bad row fails the whole transaction, retry alone so only that row is lost
+	for i := range batch {
+		if err := w.db.SaveChatMessages(batch[i : i+1]); err != nil {
+			log.Printf("Failed to save chat message %s: %v", batch[i].MessageID, err)
+		}
+	}
+}
+
+func toModel(message twitchirc.PrivateMessage) models.ChatMessage {
+	emotes := make(models.ChatEmotes, 0, len(message.Emotes))
+	for _, emote := range message.Emotes {
+		emotes = append(emotes, models.ChatEmote{
+			ID:    emote.ID,
+			Name:  stripNUL(emote.Name),
+			Count: emote.Count,
+		})
+	}
+
+	return models.ChatMessage{
+		MessageID:   message.ID,
+		Channel:     strings.ToLower(message.Channel),
+		UserID:      message.User.ID,
+		Username:    strings.ToLower(message.User.Name),
+		DisplayName: stripNUL(message.User.DisplayName),
+		Color:       message.User.Color,
+		Badges:      models.ChatBadges(message.User.Badges),
+		Emotes:      emotes,
+		Message:     stripNUL(message.Message),
+		SentAt:      message.Time,
+	}
+}
+
+// stripNUL drops NUL bytes, Postgres rejects them in TEXT and JSONB columns
+func stripNUL(s string) string {
+	return strings.ReplaceAll(s, "\x00", "")
+}
@@ -0,0 +1,68 @@
+package chatlog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"twitch-client/internal/db/models"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+func TestToModelStripsNUL(t *testing.T) {
+	message := twitchirc.PrivateMessage{
+		ID:      "1",
+		Channel: "Streamer",
+		User:    twitchirc.User{ID: "42", Name: "Viewer", DisplayName: "Vie\x00wer"},
+		Message: "hello\x00 chat\x00",
+		Emotes:  []*twitchirc.Emote{{ID: "25", Name: "Kap\x00pa", Count: 1}},
+		Time:    time.Now(),
+	}
+
+	model := toModel(message)
+	if model.Message != "hello chat" || model.DisplayName != "Viewer" || model.Emotes[0].Name != "Kappa" {
+		t.Errorf("expected NUL bytes to be stripped, got %q %q %q", model.Message, model.DisplayName, model.Emotes[0].Name)
+	}
+	if model.Channel != "streamer" || model.Username != "viewer" {
+		t.Errorf("expected lower case channel and username, got %q %q", model.Channel, model.Username)
+	}
+}
+
+func TestFlushRetriesRowsOfFailedBatch(t *testing.T) {
+	store := &fakeStore{}
+
+	w := NewWriter(store)
+	w.flush([]models.ChatMessage{
+		{MessageID: "1", Message: "first"},
+		{MessageID: "2", Message: "reject me"},
+		{MessageID: "3", Message: "third"},
+	})
+
+	if got := strings.Join(store.saved, ","); got != "1,3" {
+		t.Errorf("expected only the bad message to be lost, saved %v", store.saved)
+	}
+	if store.batches != 4 {
+		t.Errorf("expected the batch and then every row alone, got %d saves", store.batches)
+	}
+}
+
+// fakeStore fails every batch holding a message saying "reject me" as a
+// whole, like a Postgres transaction, and keeps the IDs of the saved ones
+type fakeStore struct {
+	saved   []string
+	batches int
+}
+
+func (s *fakeStore) SaveChatMessages(messages []models.ChatMessage) error {
+	s.batches++
+	for _, message := range messages {
+		if message.Message == "reject me" {
+			return errors.New("invalid byte sequence")
+		}
+	}
+	for _, message := range messages {
+		s.saved = append(s.saved, message.MessageID)
+	}
+	return nil
+}
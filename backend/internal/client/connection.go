@@ -0,0 +1,116 @@
+package client
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+// State is the state of the IRC connection
+type State string
+
+const (
+	StateDisconnected State = "disconnected"
+	StateConnecting   State = "connecting"
+	StateConnected    State = "connected"
+	StateReconnecting State = "reconnecting"
+	// StateFailed waits for new credentials, retrying can't help
+	StateFailed State = "failed"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = 2 * time.Minute
+)
+
+// Status describes the IRC connection for the dashboard
+type Status struct {
+	State State     `json:"state"`
+	Since time.Time `json:"since"`
+	// Attempt counts the failed connection attempts since the last success
+	Attempt   int    `json:"attempt,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Status returns the current state of the IRC connection
+func (c *Client) Status() Status {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	return c.status
+}
+
+// track makes client the connection whose state is reported
+func (c *Client) track(client *twitchirc.Client) {
+	c.stateMu.Lock()
+	c.active = client
+	c.stateMu.Unlock()
+
+	c.setState(client, StateConnecting, 0, nil)
+}
+
+func (c *Client) current(client *twitchirc.Client) bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	return c.active == client
+}
+
+// setState records a state change of client, changes of replaced clients are ignored
+func (c *Client) setState(client *twitchirc.Client, state State, attempt int, err error) {
+	c.stateMu.Lock()
+	if c.active != client {
+		c.stateMu.Unlock()
+		return
+	}
+
+	status := Status{State: state, Since: c.status.Since, Attempt: attempt}
+	if state != c.status.State {
+		status.Since = time.Now()
+	}
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	c.status = status
+	c.stateMu.Unlock()
+
+	if c.OnStateChange != nil {
+		c.OnStateChange(status)
+	}
+}
+
+// run keeps client connected for as long as it is the current client. Failed
+// attempts are retried with exponential backoff, a connection that was up
+// starts over from the shortest delay.
+func (c *Client) run(client *twitchirc.Client) {
+	backoff := minBackoff
+	for attempt := 1; c.current(client); attempt++ {
+		log.Printf("Connecting to Twitch (attempt %d)", attempt)
+		err := client.Connect()
+		if errors.Is(err, twitchirc.ErrClientDisconnected) || !c.current(client) {
+			return
+		}
+
+		if errors.Is(err, twitchirc.ErrLoginAuthenticationFailed) {
+			log.Printf("Twitch rejected the bot token, waiting for new credentials")
+			c.setState(client, StateFailed, attempt, err)
+			return
+		}
+
+		if c.Status().State == StateConnected {
+			attempt, backoff = 1, minBackoff
+		}
+
+		log.Printf("Error connecting to Twitch: %v, retrying in %s", err, backoff)
+		c.setState(client, StateReconnecting, attempt, err)
+
+		time.Sleep(jitter(backoff))
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// jitter spreads the delay by up to a fifth so restarts don't retry in lockstep
+func jitter(d time.Duration) time.Duration {
+	return d + time.Duration(rand.Int63n(int64(d/5)+1))
+}
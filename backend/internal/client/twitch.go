@@ -3,9 +3,11 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 	"twitch-client/internal/credentials"
@@ -13,26 +15,42 @@ import (
 	twitchirc "github.com/gempir/go-twitch-irc/v4"
 )
 
+var (
+	ErrBotNotValidated  = errors.New("bot account token has not been validated yet")
+	ErrChannelNotJoined = errors.New("channel not joined")
+	ErrInvalidChannel   = errors.New("invalid channel name")
+)
+
 type Client struct {
 	Client             *twitchirc.Client
-	currentChannel     string
+	channels           map[string]bool
 	mutex              sync.Mutex
 	OnUserJoin         func(message twitchirc.UserJoinMessage)
 	OnUserPart         func(message twitchirc.UserPartMessage)
 	MessageHandler     func(message twitchirc.PrivateMessage)
 	MessageInterceptor func(message twitchirc.PrivateMessage)
+	MessageLogger      func(message twitchirc.PrivateMessage)
+	OnStateChange      func(status Status)
 	credentials        *credentials.Credentials
 	credsChan          chan credentials.CredentialsUpdate
+
+	// state of the connection, guarded separately so hooks can read it
+	stateMu sync.Mutex
+	status  Status
+	active  *twitchirc.Client
 }
 
 func NewClient(c *credentials.Credentials, messageHandler func(message twitchirc.PrivateMessage)) *Client {
 	client := &Client{
 		MessageHandler: messageHandler,
+		channels:       make(map[string]bool),
 		credentials:    c,
 		mutex:          sync.Mutex{},
 		credsChan:      c.Subscribe(), // Subscribe to credential updates
 		OnUserJoin:     func(message twitchirc.UserJoinMessage) {},
 		OnUserPart:     func(message twitchirc.UserPartMessage) {},
+		MessageLogger:  func(message twitchirc.PrivateMessage) {},
+		status:         Status{State: StateDisconnected, Since: time.Now()},
 	}
 
 	// Start goroutine to handle credential updates
@@ -41,47 +59,75 @@ func NewClient(c *credentials.Credentials, messageHandler func(message twitchirc
 	return client
 }
 
+// NormalizeChannel lowercases a channel name and strips the leading #
+func NormalizeChannel(channel string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(channel), "#"))
+}
+
 func (c *Client) handleCredentialUpdates() {
-	// for update := range c.credsChan {
-	for range c.credsChan {
+	for update := range c.credsChan {
+		// IRC needs the bot's login, wait until the token has been validated
+		if update.Login == "" {
+			continue
+		}
 
-		if c.currentChannel != "" {
-			if err := c.Connect(c.currentChannel); err != nil {
+		if len(c.Channels()) > 0 {
+			if err := c.Connect(); err != nil {
 				log.Printf("Error reconnecting with new credentials: %v", err)
 			}
 		}
 	}
 }
 
+// failLocked reports a connection that can't be opened, a running one is kept
+func (c *Client) failLocked(err error) {
+	if c.Client == nil {
+		c.setState(nil, StateFailed, 0, err)
+	}
+}
+
 func (c *Client) Close() {
 	c.credentials.Unsubscribe(c.credsChan)
 }
 
-func (c *Client) Connect(channel string) error {
+// Connect opens a new IRC connection with the current credentials and joins
+// every channel the bot is in, an existing connection is closed first
+func (c *Client) Connect() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	return c.connectLocked()
+}
+
+func (c *Client) connectLocked() error {
 	// Get current credentials
-	clientID, oauthToken, err := c.credentials.Get()
+	_, oauthToken, err := c.credentials.Get()
 	if err != nil {
+		c.failLocked(err)
 		return err
 	}
 
+	login, _ := c.credentials.Identity()
+	if login == "" {
+		c.failLocked(ErrBotNotValidated)
+		return ErrBotNotValidated
+	}
+
 	if c.Client != nil {
 		if err := c.Client.Disconnect(); err != nil {
-			log.Printf("Error disconnecting from previous channel: %v", err)
+			log.Printf("Error disconnecting previous client: %v", err)
 		}
 	}
 
 	// Use the bot's username and OAuth token
-	c.Client = twitchirc.NewClient(clientID, fmt.Sprintf("oauth:%s", oauthToken))
-	c.currentChannel = channel
+	c.Client = twitchirc.NewClient(login, fmt.Sprintf("oauth:%s", oauthToken))
 
-	log.Printf("Creating new client for channel: %s", channel)
+	channels := c.channelsLocked()
+	log.Printf("Creating new client for channels: %s", strings.Join(channels, ", "))
 
 	c.Client.OnPrivateMessage(func(message twitchirc.PrivateMessage) {
 		c.MessageInterceptor(message)
-		c.updateMessagesLog(message)
+		c.MessageLogger(message)
 		c.MessageHandler(message)
 	})
 
@@ -93,6 +139,22 @@ func (c *Client) Connect(channel string) error {
 		c.OnUserPart(message)
 	})
 
+	client := c.Client
+	c.Client.OnConnect(func() {
+		// a client replaced while it was still dialing can't be disconnected earlier
+		if !c.current(client) {
+			client.Disconnect()
+			return
+		}
+		log.Printf("Connected to Twitch")
+		c.setState(client, StateConnected, 0, nil)
+	})
+
+	c.Client.OnReconnectMessage(func(message twitchirc.ReconnectMessage) {
+		log.Printf("Twitch asked to reconnect, reconnecting")
+		c.setState(client, StateReconnecting, 0, nil)
+	})
+
 	c.Client.OnNamesMessage(func(message twitchirc.NamesMessage) {
 		content, err := json.Marshal(message)
 		if err != nil {
@@ -101,63 +163,98 @@ func (c *Client) Connect(channel string) error {
 		log.Printf("New names message: %s", content)
 	})
 
-	// c.Client
+	c.Client.Join(channels...)
 
-	c.Client.Join(channel)
-
-	go func() {
-		for i := 0; i < 5; i++ {
-			log.Printf("Attempting to connect to channel %s (attempt %d)", channel, i+1)
-			err := c.Client.Connect()
-			if err == nil {
-				log.Printf("Successfully connected to channel: %s", channel)
-				return
-			} else {
-				log.Printf("Error connecting to Twitch: %v", err)
-			}
-			time.Sleep(time.Second * 2)
-		}
-		log.Println("Failed to connect after 5 attempts")
-	}()
+	c.track(client)
+	go c.run(client)
 
 	return nil
 }
 
-func (c *Client) GetCurrentChannel() string {
+// JoinChannel adds a channel to the connection, the first channel opens it.
+// The channel is remembered even if the bot can't connect yet, it is joined
+// as soon as the bot account gets validated.
+func (c *Client) JoinChannel(channel string) error {
+	channel = NormalizeChannel(channel)
+	if channel == "" {
+		return ErrInvalidChannel
+	}
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	return c.currentChannel
-}
 
-// function to log the message to log.json
-func (c *Client) updateMessagesLog(message twitchirc.PrivateMessage) error {
-	data, err := json.Marshal(message)
-	if err != nil {
-		log.Printf("Error marshalling message: %v", err)
+	if c.channels[channel] {
+		return nil
 	}
+	c.channels[channel] = true
 
-	// add the message to the log file while still keeping the previous messages
-	f, err := os.OpenFile("log.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Printf("Error opening log file: %v", err)
+	if c.Client == nil {
+		return c.connectLocked()
 	}
-	defer f.Close()
 
-	if _, err := f.Write(append(data, '\n')); err != nil {
-		log.Printf("Error writing to log file: %v", err)
-	}
+	c.Client.Join(channel)
+	log.Printf("Joined channel: %s", channel)
 
 	return nil
 }
 
-func (c *Client) SendMessage(message string) error {
-	c.Client.Say(c.currentChannel, message)
+// PartChannel leaves a channel, the connection stays open for the others
+func (c *Client) PartChannel(channel string) error {
+	channel = NormalizeChannel(channel)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.channels[channel] {
+		return ErrChannelNotJoined
+	}
+	delete(c.channels, channel)
+
+	if c.Client != nil {
+		c.Client.Depart(channel)
+	}
+	log.Printf("Left channel: %s", channel)
 
 	return nil
 }
 
-func (c *Client) Reply(message string, replyTo twitchirc.PrivateMessage) error {
-	c.SendMessage(fmt.Sprintf("@%s, %s", replyTo.User.Name, message))
+// Channels returns the joined channels in alphabetical order
+func (c *Client) Channels() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.channelsLocked()
+}
+
+func (c *Client) channelsLocked() []string {
+	channels := make([]string, 0, len(c.channels))
+	for channel := range c.channels {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+func (c *Client) HasChannel(channel string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.channels[NormalizeChannel(channel)]
+}
+
+func (c *Client) SendMessage(channel, message string) error {
+	channel = NormalizeChannel(channel)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.Client == nil || !c.channels[channel] {
+		return ErrChannelNotJoined
+	}
+
+	c.Client.Say(channel, message)
 
 	return nil
 }
+
+func (c *Client) Reply(message string, replyTo twitchirc.PrivateMessage) error {
+	return c.SendMessage(replyTo.Channel, fmt.Sprintf("@%s, %s", replyTo.User.Name, message))
+}
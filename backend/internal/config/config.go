@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +14,20 @@ type Config struct {
 	TwitchOAuthToken   string
 	TwitchRefreshToken string
 
+	// Tokens of the broadcaster account, the ones above belong to the bot
+	TwitchBroadcasterOAuthToken   string
+	TwitchBroadcasterRefreshToken string
+
+	TwitchHelixURL string
+
+	// Secret used to encrypt the stored credentials, they are kept in memory only when empty
+	CredentialsKey string
+
+	// Twitch logins allowed to use the dashboard besides the bot and the broadcaster
+	DashboardUsers []string
+	// Twitch logins that are dashboard owners and may sign in an empty bot or broadcaster slot
+	DashboardOwners []string
+
 	// Database
 	DBHost     string
 	DBPort     string
@@ -32,12 +47,31 @@ func LoadConfig() (*Config, error) {
 		TwitchClientSecret: os.Getenv("TWITCH_CLIENT_SECRET"),
 		TwitchOAuthToken:   os.Getenv("TWITCH_OAUTH_TOKEN"),
 		TwitchRefreshToken: os.Getenv("TWITCH_REFRESH_TOKEN"),
-		DBHost:             os.Getenv("DB_HOST"),
-		DBPort:             os.Getenv("DB_PORT"),
-		DBUser:             os.Getenv("DB_USER"),
-		DBPassword:         os.Getenv("DB_PASSWORD"),
-		DBName:             os.Getenv("DB_NAME"),
+
+		TwitchBroadcasterOAuthToken:   os.Getenv("TWITCH_BROADCASTER_OAUTH_TOKEN"),
+		TwitchBroadcasterRefreshToken: os.Getenv("TWITCH_BROADCASTER_REFRESH_TOKEN"),
+
+		TwitchHelixURL:  os.Getenv("TWITCH_HELIX_URL"),
+		CredentialsKey:  os.Getenv("CREDENTIALS_KEY"),
+		DashboardUsers:  splitList(os.Getenv("DASHBOARD_ALLOWED_USERS")),
+		DashboardOwners: splitList(os.Getenv("DASHBOARD_OWNERS")),
+		DBHost:          os.Getenv("DB_HOST"),
+		DBPort:          os.Getenv("DB_PORT"),
+		DBUser:          os.Getenv("DB_USER"),
+		DBPassword:      os.Getenv("DB_PASSWORD"),
+		DBName:          os.Getenv("DB_NAME"),
 	}
 
 	return config, nil
 }
+
+// splitList parses a comma separated list, skipping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
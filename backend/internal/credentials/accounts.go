@@ -0,0 +1,65 @@
+package credentials
+
+import "errors"
+
+var ErrUnknownRole = errors.New("unknown account role")
+
+// Role tells which Twitch account a set of credentials belongs to
+type Role string
+
+const (
+	// RoleBot is the account that reads and sends chat and moderates
+	RoleBot Role = "bot"
+	// RoleBroadcaster is the channel owner, it manages the stream, polls and predictions
+	RoleBroadcaster Role = "broadcaster"
+)
+
+// Accounts holds one credentials slot per role
+type Accounts struct {
+	Bot         *Credentials
+	Broadcaster *Credentials
+}
+
+// NewAccounts creates the credential slots of every role. When secret is set the
+// slots are persisted in store, otherwise they live in memory only.
+func NewAccounts(store Store, secret string) (*Accounts, error) {
+	if secret == "" {
+		return &Accounts{
+			Bot:         NewCredentialsManager(),
+			Broadcaster: NewCredentialsManager(),
+		}, nil
+	}
+
+	bot, err := NewStoredCredentialsManager(store, string(RoleBot), secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Installs from before the split kept the bot token in the legacy slot
+	if err := bot.adoptLegacy(); err != nil {
+		return nil, err
+	}
+
+	broadcaster, err := NewStoredCredentialsManager(store, string(RoleBroadcaster), secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Accounts{Bot: bot, Broadcaster: broadcaster}, nil
+}
+
+// For returns the credentials of the given role
+func (a *Accounts) For(role Role) (*Credentials, error) {
+	switch role {
+	case RoleBot:
+		return a.Bot, nil
+	case RoleBroadcaster:
+		return a.Broadcaster, nil
+	}
+	return nil, ErrUnknownRole
+}
+
+// Roles lists every role in a stable order
+func Roles() []Role {
+	return []Role{RoleBot, RoleBroadcaster}
+}
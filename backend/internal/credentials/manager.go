@@ -1,8 +1,12 @@
 package credentials
 
 import (
+	"crypto/cipher"
 	"errors"
 	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
 )
 
 var (
@@ -12,13 +16,24 @@ var (
 type CredentialsUpdate struct {
 	ClientID   string
 	OAuthToken string
+	// Login is empty until the token has been validated
+	Login string
 }
 
 type Credentials struct {
-	clientID    string
-	oauthToken  string
-	mu          sync.RWMutex
-	subscribers []chan CredentialsUpdate
+	clientID     string
+	oauthToken   string
+	refreshToken string
+	expiry       time.Time
+	login        string
+	userID       string
+	mu           sync.RWMutex
+	subscribers  []chan CredentialsUpdate
+
+	// Optional persistence, see NewStoredCredentialsManager
+	store     Store
+	storeName string
+	aead      cipher.AEAD
 }
 
 func NewCredentialsManager() *Credentials {
@@ -40,6 +55,7 @@ func (c *Credentials) Subscribe() chan CredentialsUpdate {
 		ch <- CredentialsUpdate{
 			ClientID:   c.clientID,
 			OAuthToken: c.oauthToken,
+			Login:      c.login,
 		}
 	}
 
@@ -62,24 +78,71 @@ func (c *Credentials) Unsubscribe(ch chan CredentialsUpdate) {
 	}
 }
 
+// Set stores a bare access token, without a refresh token it can't be renewed
 func (c *Credentials) Set(clientID, oauthToken string) {
+	c.SetToken(clientID, &oauth2.Token{AccessToken: oauthToken})
+}
+
+// SetToken stores an OAuth token along with its refresh token and expiry
+func (c *Credentials) SetToken(clientID string, token *oauth2.Token) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// The identity belongs to the previous token, it is filled in again on validation
+	if token.AccessToken != c.oauthToken {
+		c.login = ""
+		c.userID = ""
+	}
+
 	c.clientID = clientID
-	c.oauthToken = oauthToken
+	c.oauthToken = token.AccessToken
+	c.refreshToken = token.RefreshToken
+	c.expiry = token.Expiry
+	c.saveLocked()
+	c.notifyLocked()
+}
+
+// SetValidation records what /oauth2/validate said about the current token.
+// Subscribers are notified when the token's login becomes known or changes.
+func (c *Credentials) SetValidation(login, userID string, expiry time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := c.login != login
+	c.login = login
+	c.userID = userID
+	if !expiry.IsZero() {
+		c.expiry = expiry
+	}
+	c.saveLocked()
+
+	if changed {
+		c.notifyLocked()
+	}
+}
 
+// notifyLocked sends the current credentials to every subscriber, the caller must hold c.mu
+func (c *Credentials) notifyLocked() {
 	update := CredentialsUpdate{
-		ClientID:   clientID,
-		OAuthToken: oauthToken,
+		ClientID:   c.clientID,
+		OAuthToken: c.oauthToken,
+		Login:      c.login,
 	}
 
 	// Notify all subscribers of credential changes
 	for _, ch := range c.subscribers {
 		select {
 		case ch <- update: // Try to send update
-		default: // Skip if channel is full (shouldn't happen with buffered channels)
-			continue
+		default:
+			// A stale update is still waiting, replace it with the latest one
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- update:
+			default:
+			}
 		}
 	}
 }
@@ -94,3 +157,28 @@ func (c *Credentials) Get() (string, string, error) {
 
 	return c.clientID, c.oauthToken, nil
 }
+
+// Token returns the full OAuth token including the refresh token and expiry
+func (c *Credentials) Token() (*oauth2.Token, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.clientID == "" || c.oauthToken == "" {
+		return nil, ErrNoCredentials
+	}
+
+	return &oauth2.Token{
+		AccessToken:  c.oauthToken,
+		RefreshToken: c.refreshToken,
+		TokenType:    "bearer",
+		Expiry:       c.expiry,
+	}, nil
+}
+
+// Identity returns the login and user ID the token belongs to, empty until validated
+func (c *Credentials) Identity() (string, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.login, c.userID
+}
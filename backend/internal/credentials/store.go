@@ -0,0 +1,170 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+var ErrCorruptedCredentials = errors.New("stored credentials are corrupted or the key is wrong")
+
+// Store persists encrypted credentials between restarts
+type Store interface {
+	LoadCredentials(name string) ([]byte, error)
+	SaveCredentials(name string, data []byte) error
+	DeleteCredentials(name string) error
+}
+
+// legacyName is the slot credentials were saved in before the bot and
+// broadcaster accounts were split
+const legacyName = "default"
+
+type storedCredentials struct {
+	ClientID     string    `json:"client_id"`
+	OAuthToken   string    `json:"oauth_token"`
+	RefreshToken string    `json:"refresh_token"`
+	Expiry       time.Time `json:"expiry"`
+	Login        string    `json:"login"`
+	UserID       string    `json:"user_id"`
+}
+
+// NewStoredCredentialsManager creates credentials that are loaded from store under
+// name and saved back on every change, encrypted with a key derived from secret.
+func NewStoredCredentialsManager(store Store, name, secret string) (*Credentials, error) {
+	if secret == "" {
+		return nil, errors.New("credentials key is required")
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	c := NewCredentialsManager()
+	c.store = store
+	c.storeName = name
+	c.aead = aead
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Credentials) load() error {
+	data, err := c.store.LoadCredentials(c.storeName)
+	if err != nil {
+		return fmt.Errorf("failed to load credentials: %w", err)
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(data) < nonceSize {
+		return ErrCorruptedCredentials
+	}
+
+	plain, err := c.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(c.storeName))
+	if err != nil {
+		return ErrCorruptedCredentials
+	}
+
+	var stored storedCredentials
+	if err := json.Unmarshal(plain, &stored); err != nil {
+		return ErrCorruptedCredentials
+	}
+
+	c.clientID = stored.ClientID
+	c.oauthToken = stored.OAuthToken
+	c.refreshToken = stored.RefreshToken
+	c.expiry = stored.Expiry
+	c.login = stored.Login
+	c.userID = stored.UserID
+
+	return nil
+}
+
+// adoptLegacy moves the credentials of the legacy slot into c when c is still
+// empty. The slot name is part of the encryption, so the row can't simply be
+// renamed in SQL and is decrypted under its old name instead.
+func (c *Credentials) adoptLegacy() error {
+	legacy := &Credentials{store: c.store, storeName: legacyName, aead: c.aead}
+	if err := legacy.load(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if legacy.oauthToken == "" || c.oauthToken != "" {
+		return nil
+	}
+
+	c.clientID = legacy.clientID
+	c.oauthToken = legacy.oauthToken
+	c.refreshToken = legacy.refreshToken
+	c.expiry = legacy.expiry
+	c.login = legacy.login
+	c.userID = legacy.userID
+
+	if err := c.persistLocked(); err != nil {
+		return err
+	}
+
+	if err := c.store.DeleteCredentials(legacyName); err != nil {
+		return fmt.Errorf("failed to delete legacy credentials: %w", err)
+	}
+
+	log.Printf("Moved the stored credentials to the %s account", c.storeName)
+	return nil
+}
+
+// saveLocked writes the current credentials to the store, the caller must hold c.mu
+func (c *Credentials) saveLocked() {
+	if err := c.persistLocked(); err != nil {
+		log.Printf("Failed to save credentials: %v", err)
+	}
+}
+
+func (c *Credentials) persistLocked() error {
+	if c.store == nil {
+		return nil
+	}
+
+	plain, err := json.Marshal(storedCredentials{
+		ClientID:     c.clientID,
+		OAuthToken:   c.oauthToken,
+		RefreshToken: c.refreshToken,
+		Expiry:       c.expiry,
+		Login:        c.login,
+		UserID:       c.userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// The store name is authenticated too, so a blob can't be swapped between slots
+	data := c.aead.Seal(nonce, nonce, plain, []byte(c.storeName))
+	return c.store.SaveCredentials(c.storeName, data)
+}
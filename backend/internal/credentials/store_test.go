@@ -0,0 +1,100 @@
+package credentials
+
+import (
+	"errors"
+	"testing"
+)
+
+// memoryStore keeps the encrypted slots in a map
+type memoryStore map[string][]byte
+
+func (s memoryStore) LoadCredentials(name string) ([]byte, error) { return s[name], nil }
+
+func (s memoryStore) SaveCredentials(name string, data []byte) error {
+	s[name] = data
+	return nil
+}
+
+func (s memoryStore) DeleteCredentials(name string) error {
+	delete(s, name)
+	return nil
+}
+
+func TestStoredCredentialsRoundTrip(t *testing.T) {
+	store := memoryStore{}
+
+	saved, err := NewStoredCredentialsManager(store, "bot", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	saved.Set("client-id", "token")
+
+	loaded, err := NewStoredCredentialsManager(store, "bot", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clientID, token, err := loaded.Get(); err != nil || clientID != "client-id" || token != "token" {
+		t.Errorf("expected the saved credentials, got %q %q (%v)", clientID, token, err)
+	}
+
+	if _, err := NewStoredCredentialsManager(store, "bot", "other secret"); !errors.Is(err, ErrCorruptedCredentials) {
+		t.Errorf("expected a wrong key to be rejected, got %v", err)
+	}
+
+	// A blob copied into another slot doesn't decrypt there
+	store["broadcaster"] = store["bot"]
+	if _, err := NewStoredCredentialsManager(store, "broadcaster", "secret"); !errors.Is(err, ErrCorruptedCredentials) {
+		t.Errorf("expected a swapped slot to be rejected, got %v", err)
+	}
+}
+
+func TestAccountsAdoptLegacySlot(t *testing.T) {
+	store := memoryStore{}
+
+	legacy, err := NewStoredCredentialsManager(store, legacyName, "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	legacy.Set("client-id", "legacy-token")
+
+	accounts, err := NewAccounts(store, "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, token, err := accounts.Bot.Get(); err != nil || token != "legacy-token" {
+		t.Errorf("expected the bot to adopt the legacy token, got %q (%v)", token, err)
+	}
+	if _, _, err := accounts.Broadcaster.Get(); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected the broadcaster slot to stay empty, got %v", err)
+	}
+	if _, ok := store[legacyName]; ok {
+		t.Error("expected the legacy slot to be deleted")
+	}
+
+	// The next start reads the bot slot
+	accounts, err = NewAccounts(store, "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, token, _ := accounts.Bot.Get(); token != "legacy-token" {
+		t.Errorf("expected the adopted token to persist, got %q", token)
+	}
+}
+
+func TestAccountsKeepBotOverLegacySlot(t *testing.T) {
+	store := memoryStore{}
+
+	legacy, _ := NewStoredCredentialsManager(store, legacyName, "secret")
+	legacy.Set("client-id", "legacy-token")
+	bot, _ := NewStoredCredentialsManager(store, string(RoleBot), "secret")
+	bot.Set("client-id", "bot-token")
+
+	accounts, err := NewAccounts(store, "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, token, _ := accounts.Bot.Get(); token != "bot-token" {
+		t.Errorf("expected the bot slot to win, got %q", token)
+	}
+}
@@ -0,0 +1,67 @@
+package db
+
+import "twitch-client/internal/db/models"
+
+// Automod rule methods
+func (db *Database) GetAutomodRules() ([]models.AutomodRule, error) {
+	rules := []models.AutomodRule{}
+	err := db.Select(&rules, "SELECT * FROM automod_rules ORDER BY priority DESC, id")
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (db *Database) GetAutomodRule(id int) (models.AutomodRule, error) {
+	var rule models.AutomodRule
+	err := db.Get(&rule, "SELECT * FROM automod_rules WHERE id = $1", id)
+	if err != nil {
+		return models.AutomodRule{}, err
+	}
+	return rule, nil
+}
+
+func (db *Database) CreateAutomodRule(rule *models.AutomodRule) error {
+	query := `
+        INSERT INTO automod_rules (channel, name, enabled, priority, conditions, action, duration_seconds, reason)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(
+		query,
+		rule.Channel,
+		rule.Name,
+		rule.Enabled,
+		rule.Priority,
+		rule.Conditions,
+		rule.Action,
+		rule.DurationSeconds,
+		rule.Reason,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+func (db *Database) UpdateAutomodRule(rule *models.AutomodRule) error {
+	query := `
+        UPDATE automod_rules
+        SET channel = $1, name = $2, enabled = $3, priority = $4, conditions = $5, action = $6, duration_seconds = $7, reason = $8
+        WHERE id = $9
+        RETURNING created_at, updated_at`
+
+	return db.QueryRow(
+		query,
+		rule.Channel,
+		rule.Name,
+		rule.Enabled,
+		rule.Priority,
+		rule.Conditions,
+		rule.Action,
+		rule.DurationSeconds,
+		rule.Reason,
+		rule.ID,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+}
+
+func (db *Database) DeleteAutomodRule(id int) error {
+	_, err := db.Exec("DELETE FROM automod_rules WHERE id = $1", id)
+	return err
+}
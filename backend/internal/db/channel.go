@@ -0,0 +1,21 @@
+package db
+
+// Channel methods
+func (db *Database) GetChannels() ([]string, error) {
+	channels := []string{}
+	err := db.Select(&channels, "SELECT name FROM channels ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+func (db *Database) AddChannel(name string) error {
+	_, err := db.Exec("INSERT INTO channels (name) VALUES ($1) ON CONFLICT (name) DO NOTHING", name)
+	return err
+}
+
+func (db *Database) RemoveChannel(name string) error {
+	_, err := db.Exec("DELETE FROM channels WHERE name = $1", name)
+	return err
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"twitch-client/internal/db/models"
+)
+
+// chatMessageColumns leaves out the search vector, it is only used in queries
+const chatMessageColumns = "id, message_id, channel, user_id, username, display_name, color, badges, emotes, message, sent_at"
+
+// ChatHistoryFilter narrows down the chat history, zero values are ignored
+type ChatHistoryFilter struct {
+	Channel  string
+	UserID   string
+	Username string
+	From     time.Time
+	To       time.Time
+	Search   string
+	Limit    int
+}
+
+// Chat message methods
+func (db *Database) SaveChatMessages(messages []models.ChatMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Preparex(`
+        INSERT INTO chat_messages (message_id, channel, user_id, username, display_name, color, badges, emotes, message, sent_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        ON CONFLICT (message_id) DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, msg := range messages {
+		_, err := stmt.Exec(
+			msg.MessageID,
+			msg.Channel,
+			msg.UserID,
+			msg.Username,
+			msg.DisplayName,
+			msg.Color,
+			msg.Badges,
+			msg.Emotes,
+			msg.Message,
+			msg.SentAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert message %s: %w", msg.MessageID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetChatMessages returns the newest messages matching the filter first
+func (db *Database) GetChatMessages(filter ChatHistoryFilter) ([]models.ChatMessage, error) {
+	var conditions []string
+	var args []interface{}
+
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Channel != "" {
+		where("channel = $%d", filter.Channel)
+	}
+	if filter.UserID != "" {
+		where("user_id = $%d", filter.UserID)
+	}
+	if filter.Username != "" {
+		where("username = $%d", strings.ToLower(filter.Username))
+	}
+	if !filter.From.IsZero() {
+		where("sent_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("sent_at <= $%d", filter.To)
+	}
+	if filter.Search != "" {
+		where("message ILIKE '%%' || $%d || '%%'", escapeLike(filter.Search))
+	}
+
+	query := "SELECT " + chatMessageColumns + " FROM chat_messages"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY sent_at DESC LIMIT $%d", len(args))
+
+	messages := []models.ChatMessage{}
+	if err := db.Select(&messages, query, args...); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (db *Database) GetChatMessage(messageID string) (models.ChatMessage, error) {
+	var message models.ChatMessage
+	err := db.Get(&message, "SELECT "+chatMessageColumns+" FROM chat_messages WHERE message_id = $1", messageID)
+	if err != nil {
+		return models.ChatMessage{}, err
+	}
+	return message, nil
+}
+
+// SearchChatMessages runs a full-text search over stored chat, the best matches
+// come first. The query supports web search syntax: quotes, OR and -word.
+func (db *Database) SearchChatMessages(channel, search string, limit int) ([]models.ChatMessage, error) {
+	query := `
+        SELECT ` + chatMessageColumns + ` FROM chat_messages
+        WHERE search_vector @@ websearch_to_tsquery('simple', $1)
+          AND ($2 = '' OR channel = $2)
+        ORDER BY ts_rank(search_vector, websearch_to_tsquery('simple', $1)) DESC, sent_at DESC
+        LIMIT $3`
+
+	messages := []models.ChatMessage{}
+	if err := db.Select(&messages, query, search, channel, limit); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ChatUserStats sums up the stored messages of a user
+type ChatUserStats struct {
+	MessageCount int        `db:"message_count" json:"message_count"`
+	FirstSeen    *time.Time `db:"first_seen" json:"first_seen"`
+	LastSeen     *time.Time `db:"last_seen" json:"last_seen"`
+}
+
+func (db *Database) GetChatUserStats(channel, userID string) (ChatUserStats, error) {
+	query := `
+        SELECT COUNT(*) AS message_count, MIN(sent_at) AS first_seen, MAX(sent_at) AS last_seen
+        FROM chat_messages
+        WHERE user_id = $1 AND ($2 = '' OR channel = $2)`
+
+	var stats ChatUserStats
+	if err := db.Get(&stats, query, userID, channel); err != nil {
+		return ChatUserStats{}, err
+	}
+	return stats, nil
+}
+
+// escapeLike makes LIKE wildcards in user input match literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
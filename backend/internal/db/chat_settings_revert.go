@@ -0,0 +1,40 @@
+package db
+
+import "twitch-client/internal/db/models"
+
+// Chat settings revert methods
+func (db *Database) GetChatSettingsReverts(channel string) ([]models.ChatSettingsRevert, error) {
+	reverts := []models.ChatSettingsRevert{}
+	err := db.Select(&reverts, "SELECT * FROM chat_settings_reverts WHERE ($1 = '' OR channel = $1) ORDER BY revert_at", channel)
+	if err != nil {
+		return nil, err
+	}
+	return reverts, nil
+}
+
+func (db *Database) SaveChatSettingsRevert(revert models.ChatSettingsRevert) error {
+	query := `
+        INSERT INTO chat_settings_reverts (channel, mode, settings, revert_at, raid)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (channel, mode) DO UPDATE
+        SET settings = EXCLUDED.settings, revert_at = EXCLUDED.revert_at, raid = EXCLUDED.raid, attempts = 0, last_error = ''`
+
+	_, err := db.Exec(query, revert.Channel, revert.Mode, revert.Settings, revert.RevertAt, revert.Raid)
+	return err
+}
+
+// RescheduleChatSettingsRevert records a failed try and when the next one is due
+func (db *Database) RescheduleChatSettingsRevert(revert models.ChatSettingsRevert) error {
+	query := `
+        UPDATE chat_settings_reverts
+        SET revert_at = $3, attempts = $4, last_error = $5
+        WHERE channel = $1 AND mode = $2`
+
+	_, err := db.Exec(query, revert.Channel, revert.Mode, revert.RevertAt, revert.Attempts, revert.LastError)
+	return err
+}
+
+func (db *Database) DeleteChatSettingsRevert(channel, mode string) error {
+	_, err := db.Exec("DELETE FROM chat_settings_reverts WHERE channel = $1 AND mode = $2", channel, mode)
+	return err
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"time"
+	"twitch-client/internal/db/models"
+)
+
+// CommandUsage sums up how a command is used in a channel
+type CommandUsage struct {
+	Uses       int           `db:"uses" json:"uses"`
+	LastUsedAt *time.Time    `db:"last_used_at" json:"last_used_at"`
+	TopUsers   []CommandUser `db:"-" json:"top_users"`
+}
+
+type CommandUser struct {
+	Username string `db:"username" json:"username"`
+	Uses     int    `db:"uses" json:"uses"`
+}
+
+func (db *Database) RecordCommandInvocation(invocation models.CommandInvocation) error {
+	query := `
+        INSERT INTO command_invocations (channel, command, user_id, username)
+        VALUES ($1, $2, $3, $4)`
+
+	_, err := db.Exec(query, invocation.Channel, invocation.Command, invocation.UserID, invocation.Username)
+	return err
+}
+
+// GetCommandUsage returns the usage of every command used in a channel by
+// command name, along with its topUsers most frequent users
+func (db *Database) GetCommandUsage(channel string, topUsers int) (map[string]*CommandUsage, error) {
+	totals := []struct {
+		Command string `db:"command"`
+		CommandUsage
+	}{}
+	query := `
+        SELECT command, COUNT(*) AS uses, MAX(invoked_at) AS last_used_at
+        FROM command_invocations
+        WHERE channel = $1
+        GROUP BY command`
+	if err := db.Select(&totals, query, channel); err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]*CommandUsage, len(totals))
+	for _, total := range totals {
+		total.TopUsers = []CommandUser{}
+		usage[total.Command] = &total.CommandUsage
+	}
+
+	users := []struct {
+		Command string `db:"command"`
+		CommandUser
+	}{}
+	query = `
+        SELECT command, username, uses FROM (
+            SELECT command, username, COUNT(*) AS uses,
+                   ROW_NUMBER() OVER (PARTITION BY command ORDER BY COUNT(*) DESC, username) AS rank
+            FROM command_invocations
+            WHERE channel = $1
+            GROUP BY command, username
+        ) ranked
+        WHERE rank <= $2
+        ORDER BY command, rank`
+	if err := db.Select(&users, query, channel, topUsers); err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if u, ok := usage[user.Command]; ok {
+			u.TopUsers = append(u.TopUsers, user.CommandUser)
+		}
+	}
+
+	return usage, nil
+}
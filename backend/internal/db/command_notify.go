@@ -0,0 +1,54 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// commandChanges is the NOTIFY channel of command changes, the payload is the channel of the command
+const commandChanges = "command_changes"
+
+// How often an idle listener checks its connection
+const listenerPingInterval = 90 * time.Second
+
+// notifyCommandChange runs after the change committed, a failure is only
+// logged as command caches reload on their own after a while
+func (db *Database) notifyCommandChange(channel string) {
+	if _, err := db.Exec("SELECT pg_notify($1, $2)", commandChanges, channel); err != nil {
+		log.Printf("Failed to notify command change in %q: %v", channel, err)
+	}
+}
+
+// ListenCommandChanges calls onChange with the channel of every command any
+// backend instance creates, updates or deletes. Shared commands report an
+// empty channel, so does a reconnect since changes may have been missed
+// meanwhile. It blocks for as long as the process runs.
+func (db *Database) ListenCommandChanges(onChange func(channel string)) error {
+	listener := pq.NewListener(db.connStr, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Command change listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(commandChanges); err != nil {
+		return fmt.Errorf("failed to listen for command changes: %w", err)
+	}
+
+	for {
+		select {
+		case notification := <-listener.Notify:
+			// nil is sent after the connection was re-established
+			if notification == nil {
+				onChange("")
+				continue
+			}
+			onChange(notification.Extra)
+		case <-time.After(listenerPingInterval):
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Credentials methods, the data is encrypted by the credentials package before it gets here
+func (db *Database) LoadCredentials(name string) ([]byte, error) {
+	var data []byte
+	err := db.Get(&data, "SELECT data FROM credentials WHERE name = $1", name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (db *Database) SaveCredentials(name string, data []byte) error {
+	query := `
+        INSERT INTO credentials (name, data)
+        VALUES ($1, $2)
+        ON CONFLICT (name) DO UPDATE SET data = EXCLUDED.data`
+
+	_, err := db.Exec(query, name, data)
+	return err
+}
+
+func (db *Database) DeleteCredentials(name string) error {
+	_, err := db.Exec("DELETE FROM credentials WHERE name = $1", name)
+	return err
+}
@@ -0,0 +1,42 @@
+package db
+
+import "twitch-client/internal/db/models"
+
+// Dashboard user methods
+func (db *Database) GetDashboardUsers() ([]models.DashboardUser, error) {
+	users := []models.DashboardUser{}
+	err := db.Select(&users, "SELECT * FROM dashboard_users ORDER BY login")
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (db *Database) GetDashboardUser(userID string) (models.DashboardUser, error) {
+	var user models.DashboardUser
+	err := db.Get(&user, "SELECT * FROM dashboard_users WHERE user_id = $1", userID)
+	if err != nil {
+		return models.DashboardUser{}, err
+	}
+	return user, nil
+}
+
+func (db *Database) SaveDashboardUser(user *models.DashboardUser) error {
+	query := `
+        INSERT INTO dashboard_users (user_id, login, role)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id) DO UPDATE SET login = EXCLUDED.login, role = EXCLUDED.role
+        RETURNING created_at, updated_at`
+
+	return db.QueryRow(
+		query,
+		user.UserID,
+		user.Login,
+		user.Role,
+	).Scan(&user.CreatedAt, &user.UpdatedAt)
+}
+
+func (db *Database) DeleteDashboardUser(userID string) error {
+	_, err := db.Exec("DELETE FROM dashboard_users WHERE user_id = $1", userID)
+	return err
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"twitch-client/internal/db/models"
+)
+
+// Link protection methods
+func (db *Database) GetLinkProtections() ([]models.LinkProtection, error) {
+	settings := []models.LinkProtection{}
+	if err := db.Select(&settings, "SELECT * FROM link_protection ORDER BY channel"); err != nil {
+		return nil, err
+	}
+
+	allowlist := []struct {
+		Channel string `db:"channel"`
+		Domain  string `db:"domain"`
+	}{}
+	if err := db.Select(&allowlist, "SELECT channel, domain FROM link_allowlist ORDER BY domain"); err != nil {
+		return nil, err
+	}
+
+	for i := range settings {
+		settings[i].AllowedDomains = []string{}
+		for _, entry := range allowlist {
+			if entry.Channel == settings[i].Channel {
+				settings[i].AllowedDomains = append(settings[i].AllowedDomains, entry.Domain)
+			}
+		}
+	}
+
+	return settings, nil
+}
+
+// GetLinkProtection returns the settings of a channel, channels that were
+// never configured get the column defaults
+func (db *Database) GetLinkProtection(channel string) (models.LinkProtection, error) {
+	var settings models.LinkProtection
+	err := db.Get(&settings, "SELECT * FROM link_protection WHERE channel = $1", channel)
+	if errors.Is(err, sql.ErrNoRows) {
+		settings = models.LinkProtection{
+			Channel:          channel,
+			Action:           "delete",
+			ExemptVIPs:       true,
+			ExemptModerators: true,
+		}
+	} else if err != nil {
+		return models.LinkProtection{}, err
+	}
+
+	settings.AllowedDomains = []string{}
+	err = db.Select(&settings.AllowedDomains, "SELECT domain FROM link_allowlist WHERE channel = $1 ORDER BY domain", channel)
+	if err != nil {
+		return models.LinkProtection{}, err
+	}
+
+	return settings, nil
+}
+
+func (db *Database) SaveLinkProtection(settings *models.LinkProtection) error {
+	query := `
+        INSERT INTO link_protection (channel, enabled, action, duration_seconds, reason, exempt_subscribers, exempt_vips, exempt_moderators)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (channel) DO UPDATE
+        SET enabled = EXCLUDED.enabled,
+            action = EXCLUDED.action,
+            duration_seconds = EXCLUDED.duration_seconds,
+            reason = EXCLUDED.reason,
+            exempt_subscribers = EXCLUDED.exempt_subscribers,
+            exempt_vips = EXCLUDED.exempt_vips,
+            exempt_moderators = EXCLUDED.exempt_moderators
+        RETURNING created_at, updated_at`
+
+	return db.QueryRow(
+		query,
+		settings.Channel,
+		settings.Enabled,
+		settings.Action,
+		settings.DurationSeconds,
+		settings.Reason,
+		settings.ExemptSubscribers,
+		settings.ExemptVIPs,
+		settings.ExemptModerators,
+	).Scan(&settings.CreatedAt, &settings.UpdatedAt)
+}
+
+func (db *Database) AddAllowedDomain(channel, domain string) error {
+	_, err := db.Exec("INSERT INTO link_allowlist (channel, domain) VALUES ($1, $2) ON CONFLICT (channel, domain) DO NOTHING", channel, domain)
+	return err
+}
+
+func (db *Database) RemoveAllowedDomain(channel, domain string) error {
+	_, err := db.Exec("DELETE FROM link_allowlist WHERE channel = $1 AND domain = $2", channel, domain)
+	return err
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// AutomodConditions is stored as a JSONB column. Every condition that is set
+// has to match for the rule to fire, zero values are ignored.
+type AutomodConditions struct {
+	// Pattern is a regular expression matched against the message
+	Pattern string `json:"pattern,omitempty"`
+	// LinkDomains matches links to these domains and their subdomains, "*" matches any link
+	LinkDomains []string `json:"link_domains,omitempty"`
+	// PhraseListID matches messages containing a phrase of the list once both are normalized
+	PhraseListID int `json:"phrase_list_id,omitempty"`
+	// CapsRatio matches messages with at least this share of uppercase letters (0-1)
+	CapsRatio float64 `json:"caps_ratio,omitempty"`
+	// MinLength is the number of letters a message needs before CapsRatio applies
+	MinLength int `json:"min_length,omitempty"`
+	// MaxEmotes matches messages with more emotes than this
+	MaxEmotes int `json:"max_emotes,omitempty"`
+	// RepeatedChars matches runs of the same character at least this long
+	RepeatedChars int `json:"repeated_chars,omitempty"`
+	// FirstMessage matches only the first message of a user in the channel
+	FirstMessage bool `json:"first_message,omitempty"`
+	// MaxAccountAgeDays matches accounts younger than this many days
+	MaxAccountAgeDays int `json:"max_account_age_days,omitempty"`
+}
+
+func (c AutomodConditions) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *AutomodConditions) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return errors.New("automod conditions: expected []byte")
+	}
+	return json.Unmarshal(data, c)
+}
+
+type AutomodRule struct {
+	ID              int               `db:"id" json:"id"`
+	Channel         string            `db:"channel" json:"channel"`
+	Name            string            `db:"name" json:"name"`
+	Enabled         bool              `db:"enabled" json:"enabled"`
+	Priority        int               `db:"priority" json:"priority"`
+	Conditions      AutomodConditions `db:"conditions" json:"conditions"`
+	Action          string            `db:"action" json:"action"`
+	DurationSeconds int               `db:"duration_seconds" json:"duration_seconds"`
+	Reason          string            `db:"reason" json:"reason"`
+	CreatedAt       time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time         `db:"updated_at" json:"updated_at"`
+}
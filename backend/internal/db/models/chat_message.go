@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ChatBadges is stored as a JSONB column, badge name -> version
+type ChatBadges map[string]int
+
+func (b ChatBadges) Value() (driver.Value, error) {
+	if b == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(b)
+}
+
+func (b *ChatBadges) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return errors.New("chat badges: expected []byte")
+	}
+	return json.Unmarshal(data, b)
+}
+
+type ChatEmote struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ChatEmotes is stored as a JSONB column
+type ChatEmotes []ChatEmote
+
+func (e ChatEmotes) Value() (driver.Value, error) {
+	if e == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(e)
+}
+
+func (e *ChatEmotes) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return errors.New("chat emotes: expected []byte")
+	}
+	return json.Unmarshal(data, e)
+}
+
+type ChatMessage struct {
+	ID          int64      `db:"id" json:"id"`
+	MessageID   string     `db:"message_id" json:"message_id"`
+	Channel     string     `db:"channel" json:"channel"`
+	UserID      string     `db:"user_id" json:"user_id"`
+	Username    string     `db:"username" json:"username"`
+	DisplayName string     `db:"display_name" json:"display_name"`
+	Color       string     `db:"color" json:"color"`
+	Badges      ChatBadges `db:"badges" json:"badges"`
+	Emotes      ChatEmotes `db:"emotes" json:"emotes"`
+	Message     string     `db:"message" json:"message"`
+	SentAt      time.Time  `db:"sent_at" json:"sent_at"`
+}
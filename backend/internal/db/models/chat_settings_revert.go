@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ChatSettingsRevert is a chat mode change that is undone at RevertAt, it is
+// stored so a restart doesn't leave the mode on for good
+type ChatSettingsRevert struct {
+	Channel string `db:"channel" json:"channel"`
+	// Mode is the chat mode reverted, like slow or followers
+	Mode string `db:"mode" json:"mode"`
+	// Settings is the Helix chat settings update that restores the mode
+	Settings []byte    `db:"settings" json:"-"`
+	RevertAt time.Time `db:"revert_at" json:"revert_at"`
+	// Attempts counts the failed tries, RevertAt is the next one then
+	Attempts  int    `db:"attempts" json:"attempts"`
+	LastError string `db:"last_error" json:"last_error,omitempty"`
+	// Raid tells whether raid protection switched the mode, the revert runs when the raid ends
+	Raid bool `db:"raid" json:"raid"`
+}
@@ -2,15 +2,51 @@ package models
 
 import (
 	"time"
+
+	"github.com/lib/pq"
+)
+
+// Who may use a command, every level includes the ones above it
+const (
+	PermissionEveryone    = "everyone"
+	PermissionFollower    = "follower"
+	PermissionSubscriber  = "subscriber"
+	PermissionVIP         = "vip"
+	PermissionModerator   = "moderator"
+	PermissionBroadcaster = "broadcaster"
+)
+
+// PermissionLevels lists the permission levels from the lowest
+var PermissionLevels = []string{
+	PermissionEveryone,
+	PermissionFollower,
+	PermissionSubscriber,
+	PermissionVIP,
+	PermissionModerator,
+	PermissionBroadcaster,
+}
+
+// Cooldown modes, a global cooldown applies to everyone after any use
+const (
+	CooldownPerUser = "user"
+	CooldownGlobal  = "global"
+	CooldownBoth    = "both"
 )
 
 type Command struct {
-	ID              int       `db:"id" json:"id"`
-	Name            string    `db:"name" json:"name"`
-	Description     string    `db:"description" json:"description"`
-	Response        string    `db:"response" json:"response"`
-	Enabled         bool      `db:"enabled" json:"enabled"`
-	CooldownSeconds int       `db:"cooldown_seconds" json:"cooldown_seconds"`
+	ID              int            `db:"id" json:"id"`
+	Channel         string         `db:"channel" json:"channel"`
+	Name            string         `db:"name" json:"name"`
+	Description     string         `db:"description" json:"description"`
+	Response        string         `db:"response" json:"response"`
+	Aliases         pq.StringArray `db:"aliases" json:"aliases"`
+	Enabled         bool           `db:"enabled" json:"enabled"`
+	PermissionLevel string         `db:"permission_level" json:"permission_level"`
+	CooldownSeconds int            `db:"cooldown_seconds" json:"cooldown_seconds"`
+	CooldownMode    string         `db:"cooldown_mode" json:"cooldown_mode"`
+	// CooldownWarning replies in chat when the command is cooling down, otherwise it stays silent
+	CooldownWarning bool      `db:"cooldown_warning" json:"cooldown_warning"`
+	Count           int       `db:"count" json:"count"`
 	CreatedAt       time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
 }
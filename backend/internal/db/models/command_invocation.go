@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type CommandInvocation struct {
+	ID        int64     `db:"id" json:"id"`
+	Channel   string    `db:"channel" json:"channel"`
+	Command   string    `db:"command" json:"command"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	Username  string    `db:"username" json:"username"`
+	InvokedAt time.Time `db:"invoked_at" json:"invoked_at"`
+}
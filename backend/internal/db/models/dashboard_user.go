@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+	"twitch-client/internal/roles"
+)
+
+type DashboardUser struct {
+	UserID    string     `db:"user_id" json:"user_id"`
+	Login     string     `db:"login" json:"login"`
+	Role      roles.Role `db:"role" json:"role"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// LinkProtection holds how links are handled in a channel's chat
+type LinkProtection struct {
+	Channel         string `db:"channel" json:"channel"`
+	Enabled         bool   `db:"enabled" json:"enabled"`
+	Action          string `db:"action" json:"action"`
+	DurationSeconds int    `db:"duration_seconds" json:"duration_seconds"`
+	Reason          string `db:"reason" json:"reason"`
+	// Users with these badges may always post links, the broadcaster always can
+	ExemptSubscribers bool `db:"exempt_subscribers" json:"exempt_subscribers"`
+	ExemptVIPs        bool `db:"exempt_vips" json:"exempt_vips"`
+	ExemptModerators  bool `db:"exempt_moderators" json:"exempt_moderators"`
+	// AllowedDomains are stored in link_allowlist
+	AllowedDomains []string  `db:"-" json:"allowed_domains"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	ActionBan     = "ban"
+	ActionTimeout = "timeout"
+	ActionDelete  = "delete"
+	ActionWarn    = "warn"
+	ActionUnban   = "unban"
+	ActionClear   = "clear"
+)
+
+// ModerationAction is an audit log entry of a ban, timeout, deleted message,
+// warning, unban or chat clear issued through the bot
+type ModerationAction struct {
+	ID              int    `db:"id" json:"id"`
+	Channel         string `db:"channel" json:"channel"`
+	UserID          string `db:"user_id" json:"user_id"`
+	Username        string `db:"username" json:"username"`
+	Action          string `db:"action" json:"action"`
+	Reason          string `db:"reason" json:"reason"`
+	DurationSeconds int    `db:"duration_seconds" json:"duration_seconds"`
+	// Moderator is the account that carried out the action, usually the bot
+	Moderator string `db:"moderator" json:"moderator"`
+	// TriggeredBy is the dashboard user, chat moderator or automod rule behind the action
+	TriggeredBy string `db:"triggered_by" json:"triggered_by"`
+	RuleID      *int   `db:"rule_id" json:"rule_id"`
+	// MessageID and Message are the chat message that caused the action, if any
+	MessageID     string     `db:"message_id" json:"message_id"`
+	Message       string     `db:"message" json:"message"`
+	Succeeded     bool       `db:"succeeded" json:"succeeded"`
+	HelixStatus   int        `db:"helix_status" json:"helix_status"`
+	HelixResponse string     `db:"helix_response" json:"helix_response"`
+	UndoneAt      *time.Time `db:"undone_at" json:"undone_at"`
+	UndoneBy      string     `db:"undone_by" json:"undone_by"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
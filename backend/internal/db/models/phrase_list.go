@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PhraseList is a moderator-managed list of banned phrases, automod rules
+// refer to it through the phrase_list_id condition
+type PhraseList struct {
+	ID   int    `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+	// Phrases are stored in banned_phrases, "*" matches any part of a word
+	Phrases   []string  `db:"-" json:"phrases"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
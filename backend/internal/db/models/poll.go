@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type PollOption struct {
+	Title string `json:"title"`
+	Votes int    `json:"votes"`
+}
+
+// PollOptions is stored as a JSONB column
+type PollOptions []PollOption
+
+func (o PollOptions) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+func (o *PollOptions) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return errors.New("poll options: expected []byte")
+	}
+	return json.Unmarshal(data, o)
+}
+
+type Poll struct {
+	ID              int         `db:"id" json:"id"`
+	Channel         string      `db:"channel" json:"channel"`
+	Mode            string      `db:"mode" json:"mode"`
+	Question        string      `db:"question" json:"question"`
+	Options         PollOptions `db:"options" json:"options"`
+	TotalVotes      int         `db:"total_votes" json:"total_votes"`
+	DurationSeconds int         `db:"duration_seconds" json:"duration_seconds"`
+	StartedAt       time.Time   `db:"started_at" json:"started_at"`
+	EndedAt         time.Time   `db:"ended_at" json:"ended_at"`
+}
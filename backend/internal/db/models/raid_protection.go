@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	RaidModeFollowers = "followers"
+	RaidModeEmote     = "emote"
+)
+
+// RaidProtection holds how a channel reacts to spam-bot raids. Raids are
+// detected and reported either way, Enabled switches chat into Mode.
+type RaidProtection struct {
+	Channel                  string `db:"channel" json:"channel"`
+	Enabled                  bool   `db:"enabled" json:"enabled"`
+	Mode                     string `db:"mode" json:"mode"`
+	FollowersDurationMinutes int    `db:"followers_duration_minutes" json:"followers_duration_minutes"`
+	// JoinThreshold is the number of joins within 10 seconds that counts as a raid
+	JoinThreshold int `db:"join_threshold" json:"join_threshold"`
+	// SimilarThreshold is the number of near-identical first messages within 30 seconds that counts as a raid
+	SimilarThreshold int `db:"similar_threshold" json:"similar_threshold"`
+	// CalmMinutes is how long chat has to stay quiet before the mode is turned off
+	CalmMinutes int       `db:"calm_minutes" json:"calm_minutes"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Session is a dashboard login, only a hash of the session token is stored
+type Session struct {
+	TokenHash string    `db:"token_hash" json:"-"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	Login     string    `db:"login" json:"login"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
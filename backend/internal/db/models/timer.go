@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Timer posts its messages in turn every IntervalMinutes, as long as chat
+// wrote at least MinLines lines since the last post
+type Timer struct {
+	ID              int            `db:"id" json:"id"`
+	Channel         string         `db:"channel" json:"channel"`
+	Name            string         `db:"name" json:"name"`
+	Messages        pq.StringArray `db:"messages" json:"messages"`
+	IntervalMinutes int            `db:"interval_minutes" json:"interval_minutes"`
+	MinLines        int            `db:"min_lines" json:"min_lines"`
+	// LiveOnly keeps the timer quiet while the stream is offline
+	LiveOnly    bool       `db:"live_only" json:"live_only"`
+	Enabled     bool       `db:"enabled" json:"enabled"`
+	NextMessage int        `db:"next_message" json:"next_message"`
+	LastSentAt  *time.Time `db:"last_sent_at" json:"last_sent_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"twitch-client/internal/db/models"
+)
+
+// ModerationLogFilter narrows down the moderation log, zero values are ignored
+type ModerationLogFilter struct {
+	Channel  string
+	Username string
+	Action   string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// Moderation action methods
+func (db *Database) CreateModerationAction(action *models.ModerationAction) error {
+	query := `
+        INSERT INTO moderation_actions (
+            channel, user_id, username, action, reason, duration_seconds, moderator, triggered_by,
+            rule_id, message_id, message, succeeded, helix_status, helix_response
+        )
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+        RETURNING id, created_at`
+
+	return db.QueryRow(
+		query,
+		action.Channel,
+		action.UserID,
+		action.Username,
+		action.Action,
+		action.Reason,
+		action.DurationSeconds,
+		action.Moderator,
+		action.TriggeredBy,
+		action.RuleID,
+		action.MessageID,
+		action.Message,
+		action.Succeeded,
+		action.HelixStatus,
+		action.HelixResponse,
+	).Scan(&action.ID, &action.CreatedAt)
+}
+
+func (db *Database) GetModerationAction(id int) (models.ModerationAction, error) {
+	var action models.ModerationAction
+	err := db.Get(&action, "SELECT * FROM moderation_actions WHERE id = $1", id)
+	if err != nil {
+		return models.ModerationAction{}, err
+	}
+	return action, nil
+}
+
+// MarkModerationActionUndone records who undid an action, it returns
+// sql.ErrNoRows when the action was already undone
+func (db *Database) MarkModerationActionUndone(id int, undoneBy string) error {
+	result, err := db.Exec("UPDATE moderation_actions SET undone_at = NOW(), undone_by = $2 WHERE id = $1 AND undone_at IS NULL", id, undoneBy)
+	if err != nil {
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetUserModerationActions returns the newest bans and timeouts of a user
+// first, limited to a channel unless it is empty
+func (db *Database) GetUserModerationActions(channel, userID string, limit int) ([]models.ModerationAction, error) {
+	query := `
+        SELECT * FROM moderation_actions
+        WHERE user_id = $1 AND ($2 = '' OR channel = $2) AND action IN ($3, $4)
+        ORDER BY created_at DESC
+        LIMIT $5`
+
+	actions := []models.ModerationAction{}
+	err := db.Select(&actions, query, userID, channel, models.ActionBan, models.ActionTimeout, limit)
+	if err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// GetModerationLog returns the newest moderation actions matching the filter first
+func (db *Database) GetModerationLog(filter ModerationLogFilter) ([]models.ModerationAction, error) {
+	var conditions []string
+	var args []interface{}
+
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Channel != "" {
+		where("channel = $%d", filter.Channel)
+	}
+	if filter.Username != "" {
+		where("username = $%d", strings.ToLower(filter.Username))
+	}
+	if filter.Action != "" {
+		where("action = $%d", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at <= $%d", filter.To)
+	}
+
+	query := "SELECT * FROM moderation_actions"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	actions := []models.ModerationAction{}
+	if err := db.Select(&actions, query, args...); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
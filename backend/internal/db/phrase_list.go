@@ -0,0 +1,90 @@
+package db
+
+import (
+	"fmt"
+	"twitch-client/internal/db/models"
+)
+
+// Phrase list methods
+func (db *Database) GetPhraseLists() ([]models.PhraseList, error) {
+	lists := []models.PhraseList{}
+	if err := db.Select(&lists, "SELECT * FROM phrase_lists ORDER BY name"); err != nil {
+		return nil, err
+	}
+
+	phrases := []struct {
+		ListID int    `db:"list_id"`
+		Phrase string `db:"phrase"`
+	}{}
+	if err := db.Select(&phrases, "SELECT list_id, phrase FROM banned_phrases ORDER BY phrase"); err != nil {
+		return nil, err
+	}
+
+	for i := range lists {
+		lists[i].Phrases = []string{}
+		for _, p := range phrases {
+			if p.ListID == lists[i].ID {
+				lists[i].Phrases = append(lists[i].Phrases, p.Phrase)
+			}
+		}
+	}
+
+	return lists, nil
+}
+
+func (db *Database) GetPhraseList(id int) (models.PhraseList, error) {
+	var list models.PhraseList
+	if err := db.Get(&list, "SELECT * FROM phrase_lists WHERE id = $1", id); err != nil {
+		return models.PhraseList{}, err
+	}
+
+	list.Phrases = []string{}
+	if err := db.Select(&list.Phrases, "SELECT phrase FROM banned_phrases WHERE list_id = $1 ORDER BY phrase", id); err != nil {
+		return models.PhraseList{}, err
+	}
+
+	return list, nil
+}
+
+// SavePhraseList creates the list when it has no ID yet, otherwise it renames
+// it. The phrases of the list are replaced in both cases.
+func (db *Database) SavePhraseList(list *models.PhraseList) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if list.ID == 0 {
+		err = tx.QueryRow(
+			"INSERT INTO phrase_lists (name) VALUES ($1) RETURNING id, created_at, updated_at",
+			list.Name,
+		).Scan(&list.ID, &list.CreatedAt, &list.UpdatedAt)
+	} else {
+		err = tx.QueryRow(
+			"UPDATE phrase_lists SET name = $1 WHERE id = $2 RETURNING created_at, updated_at",
+			list.Name, list.ID,
+		).Scan(&list.CreatedAt, &list.UpdatedAt)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM banned_phrases WHERE list_id = $1", list.ID); err != nil {
+		return fmt.Errorf("failed to clear phrases: %w", err)
+	}
+
+	for _, phrase := range list.Phrases {
+		_, err := tx.Exec("INSERT INTO banned_phrases (list_id, phrase) VALUES ($1, $2) ON CONFLICT DO NOTHING", list.ID, phrase)
+		if err != nil {
+			return fmt.Errorf("failed to insert phrase %q: %w", phrase, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (db *Database) DeletePhraseList(id int) error {
+	_, err := db.Exec("DELETE FROM phrase_lists WHERE id = $1", id)
+	return err
+}
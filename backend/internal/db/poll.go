@@ -0,0 +1,32 @@
+package db
+
+import "twitch-client/internal/db/models"
+
+// Poll methods
+func (db *Database) CreatePoll(poll *models.Poll) error {
+	query := `
+        INSERT INTO polls (channel, mode, question, options, total_votes, duration_seconds, started_at, ended_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id`
+
+	return db.QueryRow(
+		query,
+		poll.Channel,
+		poll.Mode,
+		poll.Question,
+		poll.Options,
+		poll.TotalVotes,
+		poll.DurationSeconds,
+		poll.StartedAt,
+		poll.EndedAt,
+	).Scan(&poll.ID)
+}
+
+func (db *Database) GetPolls(channel string, limit int) ([]models.Poll, error) {
+	polls := []models.Poll{}
+	err := db.Select(&polls, "SELECT * FROM polls WHERE channel = $1 ORDER BY ended_at DESC LIMIT $2", channel, limit)
+	if err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
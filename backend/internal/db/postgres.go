@@ -1,6 +1,8 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,11 +12,13 @@ import (
 	"twitch-client/internal/db/models"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type Database struct {
 	*sqlx.DB
+	// connStr opens the extra connection LISTEN needs
+	connStr string
 }
 
 func NewPostgresDB(cfg *config.Config) (*Database, error) {
@@ -28,7 +32,7 @@ func NewPostgresDB(cfg *config.Config) (*Database, error) {
 		return nil, fmt.Errorf("error connecting to database: %v", err)
 	}
 
-	return &Database{db}, nil
+	return &Database{DB: db, connStr: connStr}, nil
 }
 
 func (db *Database) ApplyMigrations() error {
@@ -116,9 +120,46 @@ func (db *Database) GetAllCommands() ([]models.Command, error) {
 	return commands, nil
 }
 
-func (db *Database) GetCommandByName(name string) (models.Command, error) {
+// GetChannelCommands returns the commands of a channel along with the shared
+// ones, a channel command hides a shared command with the same name
+func (db *Database) GetChannelCommands(channel string) ([]models.Command, error) {
+	var commands []models.Command
+	query := `
+        SELECT DISTINCT ON (name) * FROM commands
+        WHERE channel = $1 OR channel = ''
+        ORDER BY name, channel DESC`
+
+	err := db.Select(&commands, query, channel)
+	if err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+func (db *Database) GetCommand(id int) (models.Command, error) {
 	var cmd models.Command
-	err := db.Get(&cmd, "SELECT * FROM commands WHERE name = $1", name)
+	if err := db.Get(&cmd, "SELECT * FROM commands WHERE id = $1", id); err != nil {
+		return models.Command{}, err
+	}
+	return cmd, nil
+}
+
+// GetCommandByName finds a command by its name or one of its aliases. A channel
+// command beats a shared one, then a name beats an alias. Shared commands
+// hidden by a channel command of the same name are skipped along with their
+// aliases, like in GetChannelCommands.
+func (db *Database) GetCommandByName(channel, name string) (models.Command, error) {
+	var cmd models.Command
+	query := `
+        SELECT * FROM commands c
+        WHERE (c.name = $2 OR $2 = ANY(c.aliases)) AND (c.channel = $1 OR c.channel = '')
+          AND NOT (c.channel = '' AND $1 <> '' AND EXISTS (
+              SELECT 1 FROM commands WHERE channel = $1 AND name = c.name
+          ))
+        ORDER BY c.channel DESC, c.name = $2 DESC
+        LIMIT 1`
+
+	err := db.Get(&cmd, query, channel, name)
 	if err != nil {
 		return models.Command{}, err
 	}
@@ -127,54 +168,105 @@ func (db *Database) GetCommandByName(name string) (models.Command, error) {
 
 func (db *Database) CreateCommand(cmd *models.Command) error {
 	query := `
-        INSERT INTO commands (name, description, response, enabled, cooldown_seconds)
-        VALUES ($1, $2, $3, $4, $5)
-        RETURNING id, created_at, updated_at`
+        INSERT INTO commands (channel, name, description, response, aliases, enabled, permission_level, cooldown_seconds, cooldown_mode, cooldown_warning)
+        VALUES ($1, $2, $3, $4, COALESCE($5::TEXT[], '{}'), $6, $7, $8, $9, $10)
+        RETURNING id, count, created_at, updated_at`
 
-	return db.QueryRow(
+	err := db.QueryRow(
 		query,
+		cmd.Channel,
 		cmd.Name,
 		cmd.Description,
 		cmd.Response,
+		cmd.Aliases,
 		cmd.Enabled,
+		cmd.PermissionLevel,
 		cmd.CooldownSeconds,
-	).Scan(&cmd.ID, &cmd.CreatedAt, &cmd.UpdatedAt)
+		cmd.CooldownMode,
+		cmd.CooldownWarning,
+	).Scan(&cmd.ID, &cmd.Count, &cmd.CreatedAt, &cmd.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	db.notifyCommandChange(cmd.Channel)
+	return nil
 }
 
 func (db *Database) UpdateCommand(cmd *models.Command) (*models.Command, error) {
 	query := `
         UPDATE commands
-        SET name = $1, description = $2, response = $3, enabled = $4, cooldown_seconds = $5
-        WHERE id = $6
-        RETURNING id, name, description, response, enabled, cooldown_seconds, created_at, updated_at`
+        SET name = $1, description = $2, response = $3, aliases = COALESCE($4::TEXT[], '{}'), enabled = $5,
+            permission_level = $6, cooldown_seconds = $7, cooldown_mode = $8, cooldown_warning = $9
+        WHERE id = $10
+        RETURNING *`
 
-	err := db.QueryRow(
+	err := db.QueryRowx(
 		query,
 		cmd.Name,
 		cmd.Description,
 		cmd.Response,
+		cmd.Aliases,
 		cmd.Enabled,
+		cmd.PermissionLevel,
 		cmd.CooldownSeconds,
+		cmd.CooldownMode,
+		cmd.CooldownWarning,
 		cmd.ID,
-	).Scan(
-		&cmd.ID,
-		&cmd.Name,
-		&cmd.Description,
-		&cmd.Response,
-		&cmd.Enabled,
-		&cmd.CooldownSeconds,
-		&cmd.CreatedAt,
-		&cmd.UpdatedAt,
-	)
+	).StructScan(cmd)
 
 	if err != nil {
 		return nil, err
 	}
 
+	db.notifyCommandChange(cmd.Channel)
 	return cmd, nil
 }
 
-func (db *Database) DeleteCommand(id int) error {
-	_, err := db.Exec("DELETE FROM commands WHERE id = $1", id)
+// CommandNameTaken tells whether another command of the channel already uses
+// one of the names, either as its name or as an alias
+func (db *Database) CommandNameTaken(channel string, id int, names []string) (bool, error) {
+	query := `
+        SELECT EXISTS (
+            SELECT 1 FROM commands
+            WHERE channel = $1 AND id <> $2 AND (name = ANY($3) OR aliases && $3)
+        )`
+
+	var taken bool
+	if err := db.Get(&taken, query, channel, id, pq.StringArray(names)); err != nil {
+		return false, err
+	}
+	return taken, nil
+}
+
+func (db *Database) GetCommandCount(id int) (int, error) {
+	var count int
+	err := db.Get(&count, "SELECT count FROM commands WHERE id = $1", id)
+	return count, err
+}
+
+// AddCommandCount changes the counter of a command by delta and returns the new value
+func (db *Database) AddCommandCount(id, delta int) (int, error) {
+	var count int
+	err := db.QueryRow("UPDATE commands SET count = count + $2 WHERE id = $1 RETURNING count", id, delta).Scan(&count)
+	return count, err
+}
+
+func (db *Database) SetCommandCount(id, count int) error {
+	_, err := db.Exec("UPDATE commands SET count = $2 WHERE id = $1", id, count)
 	return err
 }
+
+func (db *Database) DeleteCommand(id int) error {
+	var channel string
+	err := db.QueryRow("DELETE FROM commands WHERE id = $1 RETURNING channel", id).Scan(&channel)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	db.notifyCommandChange(channel)
+	return nil
+}
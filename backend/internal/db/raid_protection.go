@@ -0,0 +1,65 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"twitch-client/internal/db/models"
+)
+
+// DefaultRaidProtection mirrors the column defaults for channels that were never configured
+func DefaultRaidProtection(channel string) models.RaidProtection {
+	return models.RaidProtection{
+		Channel:                  channel,
+		Mode:                     models.RaidModeFollowers,
+		FollowersDurationMinutes: 10,
+		JoinThreshold:            30,
+		SimilarThreshold:         5,
+		CalmMinutes:              3,
+	}
+}
+
+// Raid protection methods
+func (db *Database) GetRaidProtections() ([]models.RaidProtection, error) {
+	settings := []models.RaidProtection{}
+	if err := db.Select(&settings, "SELECT * FROM raid_protection ORDER BY channel"); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (db *Database) GetRaidProtection(channel string) (models.RaidProtection, error) {
+	var settings models.RaidProtection
+	err := db.Get(&settings, "SELECT * FROM raid_protection WHERE channel = $1", channel)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultRaidProtection(channel), nil
+	}
+	if err != nil {
+		return models.RaidProtection{}, err
+	}
+	return settings, nil
+}
+
+func (db *Database) SaveRaidProtection(settings *models.RaidProtection) error {
+	query := `
+        INSERT INTO raid_protection (channel, enabled, mode, followers_duration_minutes, join_threshold, similar_threshold, calm_minutes)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (channel) DO UPDATE
+        SET enabled = EXCLUDED.enabled,
+            mode = EXCLUDED.mode,
+            followers_duration_minutes = EXCLUDED.followers_duration_minutes,
+            join_threshold = EXCLUDED.join_threshold,
+            similar_threshold = EXCLUDED.similar_threshold,
+            calm_minutes = EXCLUDED.calm_minutes
+        RETURNING created_at, updated_at`
+
+	return db.QueryRow(
+		query,
+		settings.Channel,
+		settings.Enabled,
+		settings.Mode,
+		settings.FollowersDurationMinutes,
+		settings.JoinThreshold,
+		settings.SimilarThreshold,
+		settings.CalmMinutes,
+	).Scan(&settings.CreatedAt, &settings.UpdatedAt)
+}
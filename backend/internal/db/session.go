@@ -0,0 +1,38 @@
+package db
+
+import "twitch-client/internal/db/models"
+
+// Session methods
+func (db *Database) CreateSession(session *models.Session) error {
+	query := `
+        INSERT INTO dashboard_sessions (token_hash, user_id, login, expires_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING created_at`
+
+	return db.QueryRow(
+		query,
+		session.TokenHash,
+		session.UserID,
+		session.Login,
+		session.ExpiresAt,
+	).Scan(&session.CreatedAt)
+}
+
+func (db *Database) GetSession(tokenHash string) (models.Session, error) {
+	var session models.Session
+	err := db.Get(&session, "SELECT * FROM dashboard_sessions WHERE token_hash = $1 AND expires_at > NOW()", tokenHash)
+	if err != nil {
+		return models.Session{}, err
+	}
+	return session, nil
+}
+
+func (db *Database) DeleteSession(tokenHash string) error {
+	_, err := db.Exec("DELETE FROM dashboard_sessions WHERE token_hash = $1", tokenHash)
+	return err
+}
+
+func (db *Database) DeleteExpiredSessions() error {
+	_, err := db.Exec("DELETE FROM dashboard_sessions WHERE expires_at <= NOW()")
+	return err
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"time"
+	"twitch-client/internal/db/models"
+)
+
+// Timer methods
+func (db *Database) GetTimers() ([]models.Timer, error) {
+	timers := []models.Timer{}
+	if err := db.Select(&timers, "SELECT * FROM timers ORDER BY channel, name"); err != nil {
+		return nil, err
+	}
+	return timers, nil
+}
+
+func (db *Database) GetChannelTimers(channel string) ([]models.Timer, error) {
+	timers := []models.Timer{}
+	if err := db.Select(&timers, "SELECT * FROM timers WHERE channel = $1 ORDER BY name", channel); err != nil {
+		return nil, err
+	}
+	return timers, nil
+}
+
+func (db *Database) GetTimer(id int) (models.Timer, error) {
+	var timer models.Timer
+	if err := db.Get(&timer, "SELECT * FROM timers WHERE id = $1", id); err != nil {
+		return models.Timer{}, err
+	}
+	return timer, nil
+}
+
+func (db *Database) CreateTimer(timer *models.Timer) error {
+	query := `
+        INSERT INTO timers (channel, name, messages, interval_minutes, min_lines, live_only, enabled)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING *`
+
+	return db.QueryRowx(
+		query,
+		timer.Channel,
+		timer.Name,
+		timer.Messages,
+		timer.IntervalMinutes,
+		timer.MinLines,
+		timer.LiveOnly,
+		timer.Enabled,
+	).StructScan(timer)
+}
+
+// UpdateTimer changes the settings of a timer, its channel and rotation stay
+func (db *Database) UpdateTimer(timer *models.Timer) error {
+	query := `
+        UPDATE timers
+        SET name = $1, messages = $2, interval_minutes = $3, min_lines = $4, live_only = $5, enabled = $6
+        WHERE id = $7
+        RETURNING *`
+
+	return db.QueryRowx(
+		query,
+		timer.Name,
+		timer.Messages,
+		timer.IntervalMinutes,
+		timer.MinLines,
+		timer.LiveOnly,
+		timer.Enabled,
+		timer.ID,
+	).StructScan(timer)
+}
+
+// SaveTimerState remembers where the rotation of a timer continues after a post
+func (db *Database) SaveTimerState(id, nextMessage int, lastSentAt time.Time) error {
+	_, err := db.Exec("UPDATE timers SET next_message = $2, last_sent_at = $3 WHERE id = $1", id, nextMessage, lastSentAt)
+	return err
+}
+
+func (db *Database) DeleteTimer(id int) error {
+	_, err := db.Exec("DELETE FROM timers WHERE id = $1", id)
+	return err
+}
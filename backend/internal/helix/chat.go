@@ -0,0 +1,138 @@
+package helix
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// ChatSettings are the chat modes of a channel
+type ChatSettings struct {
+	BroadcasterID                 string `json:"broadcaster_id"`
+	EmoteMode                     bool   `json:"emote_mode"`
+	FollowerMode                  bool   `json:"follower_mode"`
+	FollowerModeDuration          *int   `json:"follower_mode_duration"`
+	NonModeratorChatDelay         bool   `json:"non_moderator_chat_delay"`
+	NonModeratorChatDelayDuration *int   `json:"non_moderator_chat_delay_duration"`
+	SlowMode                      bool   `json:"slow_mode"`
+	SlowModeWaitTime              *int   `json:"slow_mode_wait_time"`
+	SubscriberMode                bool   `json:"subscriber_mode"`
+	UniqueChatMode                bool   `json:"unique_chat_mode"`
+}
+
+// ChatSettingsUpdate changes only the modes that are set
+type ChatSettingsUpdate struct {
+	EmoteMode                     *bool `json:"emote_mode,omitempty"`
+	FollowerMode                  *bool `json:"follower_mode,omitempty"`
+	FollowerModeDuration          *int  `json:"follower_mode_duration,omitempty"`
+	NonModeratorChatDelay         *bool `json:"non_moderator_chat_delay,omitempty"`
+	NonModeratorChatDelayDuration *int  `json:"non_moderator_chat_delay_duration,omitempty"`
+	SlowMode                      *bool `json:"slow_mode,omitempty"`
+	SlowModeWaitTime              *int  `json:"slow_mode_wait_time,omitempty"`
+	SubscriberMode                *bool `json:"subscriber_mode,omitempty"`
+	UniqueChatMode                *bool `json:"unique_chat_mode,omitempty"`
+}
+
+type chatSettingsResponse struct {
+	Data []ChatSettings `json:"data"`
+}
+
+// GetChatSettings returns the chat modes of a channel as a moderator sees
+// them, which includes the non-moderator chat delay
+func (c *Client) GetChatSettings(ctx context.Context, broadcasterID, moderatorID string) (ChatSettings, error) {
+	query := url.Values{"broadcaster_id": {broadcasterID}, "moderator_id": {moderatorID}}
+
+	var response chatSettingsResponse
+	if err := c.Get(ctx, "/chat/settings", query, &response); err != nil {
+		return ChatSettings{}, err
+	}
+
+	if len(response.Data) == 0 {
+		return ChatSettings{}, fmt.Errorf("no chat settings returned for broadcaster: %s", broadcasterID)
+	}
+
+	return response.Data[0], nil
+}
+
+// UpdateChatSettings changes the chat modes of a channel and returns all of them
+func (c *Client) UpdateChatSettings(ctx context.Context, broadcasterID, moderatorID string, update ChatSettingsUpdate) (ChatSettings, error) {
+	query := url.Values{"broadcaster_id": {broadcasterID}, "moderator_id": {moderatorID}}
+
+	var response chatSettingsResponse
+	if err := c.Patch(ctx, "/chat/settings", query, update, &response); err != nil {
+		return ChatSettings{}, err
+	}
+
+	if len(response.Data) == 0 {
+		return ChatSettings{}, fmt.Errorf("no chat settings returned for broadcaster: %s", broadcasterID)
+	}
+
+	return response.Data[0], nil
+}
+
+// Chat modes as split by ChatSettingsUpdate.Modes
+const (
+	ChatModeEmote       = "emote"
+	ChatModeFollowers   = "followers"
+	ChatModeSlow        = "slow"
+	ChatModeSubscribers = "subscribers"
+	ChatModeUnique      = "unique"
+	ChatModeDelay       = "delay"
+)
+
+// Modes splits an update by chat mode, a mode and its duration go together
+func (u ChatSettingsUpdate) Modes() map[string]ChatSettingsUpdate {
+	modes := make(map[string]ChatSettingsUpdate)
+	if u.EmoteMode != nil {
+		modes[ChatModeEmote] = ChatSettingsUpdate{EmoteMode: u.EmoteMode}
+	}
+	if u.FollowerMode != nil || u.FollowerModeDuration != nil {
+		modes[ChatModeFollowers] = ChatSettingsUpdate{FollowerMode: u.FollowerMode, FollowerModeDuration: u.FollowerModeDuration}
+	}
+	if u.SlowMode != nil || u.SlowModeWaitTime != nil {
+		modes[ChatModeSlow] = ChatSettingsUpdate{SlowMode: u.SlowMode, SlowModeWaitTime: u.SlowModeWaitTime}
+	}
+	if u.SubscriberMode != nil {
+		modes[ChatModeSubscribers] = ChatSettingsUpdate{SubscriberMode: u.SubscriberMode}
+	}
+	if u.UniqueChatMode != nil {
+		modes[ChatModeUnique] = ChatSettingsUpdate{UniqueChatMode: u.UniqueChatMode}
+	}
+	if u.NonModeratorChatDelay != nil || u.NonModeratorChatDelayDuration != nil {
+		modes[ChatModeDelay] = ChatSettingsUpdate{NonModeratorChatDelay: u.NonModeratorChatDelay, NonModeratorChatDelayDuration: u.NonModeratorChatDelayDuration}
+	}
+	return modes
+}
+
+// Revert returns the update that restores the modes changed by u to how they are in s
+func (s ChatSettings) Revert(u ChatSettingsUpdate) ChatSettingsUpdate {
+	var revert ChatSettingsUpdate
+	if u.EmoteMode != nil {
+		revert.EmoteMode = &s.EmoteMode
+	}
+	if u.FollowerMode != nil || u.FollowerModeDuration != nil {
+		revert.FollowerMode = &s.FollowerMode
+		if s.FollowerMode {
+			revert.FollowerModeDuration = s.FollowerModeDuration
+		}
+	}
+	if u.SlowMode != nil || u.SlowModeWaitTime != nil {
+		revert.SlowMode = &s.SlowMode
+		if s.SlowMode {
+			revert.SlowModeWaitTime = s.SlowModeWaitTime
+		}
+	}
+	if u.SubscriberMode != nil {
+		revert.SubscriberMode = &s.SubscriberMode
+	}
+	if u.UniqueChatMode != nil {
+		revert.UniqueChatMode = &s.UniqueChatMode
+	}
+	if u.NonModeratorChatDelay != nil || u.NonModeratorChatDelayDuration != nil {
+		revert.NonModeratorChatDelay = &s.NonModeratorChatDelay
+		if s.NonModeratorChatDelay {
+			revert.NonModeratorChatDelayDuration = s.NonModeratorChatDelayDuration
+		}
+	}
+	return revert
+}
@@ -0,0 +1,40 @@
+package helix
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrUnauthorized = errors.New("helix: unauthorized")
+	ErrNotFound     = errors.New("helix: not found")
+	ErrRateLimited  = errors.New("helix: rate limited")
+)
+
+// APIError is returned for every non-2xx response and carries the error body sent by Twitch
+type APIError struct {
+	StatusCode int    `json:"status"`
+	ErrorText  string `json:"error"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("helix: %d %s: %s", e.StatusCode, e.ErrorText, e.Message)
+	}
+	return fmt.Sprintf("helix: unexpected status code: %d", e.StatusCode)
+}
+
+// Is lets callers match an APIError with errors.Is(err, helix.ErrNotFound) and friends
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
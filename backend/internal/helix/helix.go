@@ -0,0 +1,175 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"twitch-client/internal/credentials"
+)
+
+const (
+	DefaultBaseURL = "https://api.twitch.tv/helix"
+
+	// How many times a request rejected with 429 is retried after the bucket resets
+	maxRateLimitRetries = 3
+)
+
+// sharedHTTPClient is reused by every Client so connections to Twitch are pooled
+var sharedHTTPClient = &http.Client{
+	Timeout: 15 * time.Second,
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		MaxIdleConns:          20,
+		IdleConnTimeout:       90 * time.Second,
+	},
+}
+
+// Client sends authenticated requests to the Twitch Helix API
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	credentials *credentials.Credentials
+	limiter     *rateLimiter
+}
+
+// NewClient creates a Helix client authenticated with creds. An empty baseURL
+// falls back to the Twitch API, any other value lets you point it at a mock.
+func NewClient(creds *credentials.Credentials, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		httpClient:  sharedHTTPClient,
+		credentials: creds,
+		limiter:     newRateLimiter(),
+	}
+}
+
+func (c *Client) Get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+func (c *Client) Post(ctx context.Context, path string, query url.Values, body, out interface{}) error {
+	return c.Do(ctx, http.MethodPost, path, query, body, out)
+}
+
+func (c *Client) Patch(ctx context.Context, path string, query url.Values, body, out interface{}) error {
+	return c.Do(ctx, http.MethodPatch, path, query, body, out)
+}
+
+func (c *Client) Put(ctx context.Context, path string, query url.Values, body, out interface{}) error {
+	return c.Do(ctx, http.MethodPut, path, query, body, out)
+}
+
+func (c *Client) Delete(ctx context.Context, path string, query url.Values) error {
+	return c.Do(ctx, http.MethodDelete, path, query, nil, nil)
+}
+
+// Do sends a request to path (relative to the base URL) and decodes the JSON
+// response into out. A nil out discards the response body. Requests wait for
+// the rate limit bucket and are retried when Twitch answers with 429.
+// Non-2xx responses are returned as *APIError.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var jsonBody []byte
+	if body != nil {
+		var err error
+		if jsonBody, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+
+		err := c.send(ctx, method, endpoint, jsonBody, out)
+		if errors.Is(err, ErrRateLimited) && attempt < maxRateLimitRetries {
+			continue
+		}
+		return err
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, endpoint string, jsonBody []byte, out interface{}) error {
+	clientID, oauthToken, err := c.credentials.Get()
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	var reader io.Reader
+	if jsonBody != nil {
+		reader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Client-ID", clientID)
+	req.Header.Set("Authorization", "Bearer "+oauthToken)
+	if jsonBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.limiter.exhaust(resp.Header)
+		return decodeError(resp)
+	}
+	c.limiter.update(resp.Header)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := json.Unmarshal(body, apiErr); err != nil && len(body) > 0 {
+		apiErr.Message = string(body)
+	}
+	// Twitch repeats the status in the body, make sure the real one wins
+	apiErr.StatusCode = resp.StatusCode
+
+	return apiErr
+}
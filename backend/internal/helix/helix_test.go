@@ -0,0 +1,89 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"twitch-client/internal/credentials"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	creds := credentials.NewCredentialsManager()
+	creds.Set("client-id", "token")
+
+	return NewClient(creds, server.URL+"/")
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    error
+		message string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"error":"Unauthorized","status":401,"message":"Invalid OAuth token"}`, ErrUnauthorized, "Invalid OAuth token"},
+		{"not found", http.StatusNotFound, `{"error":"Not Found","status":404,"message":"poll not found"}`, ErrNotFound, "poll not found"},
+		{"body status is ignored", http.StatusNotFound, `{"error":"Bad Request","status":400,"message":"wrong"}`, ErrNotFound, "wrong"},
+		{"plain text body", http.StatusBadRequest, `missing broadcaster_id`, nil, "missing broadcaster_id"},
+		{"empty body", http.StatusInternalServerError, ``, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			})
+
+			err := client.Get(context.Background(), "/polls", nil, nil)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.message {
+				t.Errorf("unexpected error: %+v", apiErr)
+			}
+
+			for _, sentinel := range []error{ErrUnauthorized, ErrNotFound, ErrRateLimited} {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v", sentinel, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClientRequest(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users" || r.URL.Query().Get("login") != "streamer" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		if r.Header.Get("Client-ID") != "client-id" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		io.WriteString(w, `{"data":[{"id":"1234"}]}`)
+	})
+
+	id, err := client.GetUserID(context.Background(), "streamer")
+	if err != nil || id != "1234" {
+		t.Errorf("expected 1234, got %q (%v)", id, err)
+	}
+}
+
+func TestClientWithoutCredentials(t *testing.T) {
+	client := NewClient(credentials.NewCredentialsManager(), "http://127.0.0.1:0")
+
+	if err := client.Get(context.Background(), "/users", nil, nil); !errors.Is(err, credentials.ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+}
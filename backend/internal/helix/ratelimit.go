@@ -0,0 +1,96 @@
+package helix
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Twitch gives every app+user token a bucket of 800 points per minute
+	defaultBucketSize = 800
+	defaultRefill     = time.Minute
+)
+
+// rateLimiter is a token bucket that mirrors the Ratelimit-* headers sent by Twitch.
+// Requests take a token before they are sent and wait in line when the bucket is empty.
+type rateLimiter struct {
+	limit     int
+	remaining int
+	reset     time.Time
+	mu        sync.Mutex
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limit:     defaultBucketSize,
+		remaining: defaultBucketSize,
+	}
+}
+
+// wait blocks until a token is available or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+
+		if !l.reset.IsZero() && !now.Before(l.reset) {
+			l.remaining = l.limit
+			l.reset = time.Time{}
+		}
+
+		if l.remaining > 0 {
+			l.remaining--
+			l.mu.Unlock()
+			return nil
+		}
+
+		// We ran dry without hearing from Twitch when the bucket refills
+		if l.reset.IsZero() {
+			l.reset = now.Add(defaultRefill)
+		}
+		delay := l.reset.Sub(now)
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// update syncs the bucket with the Ratelimit-Limit, Ratelimit-Remaining and
+// Ratelimit-Reset headers of a response
+func (l *rateLimiter) update(header http.Header) {
+	limit, limitErr := strconv.Atoi(header.Get("Ratelimit-Limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("Ratelimit-Remaining"))
+	reset, resetErr := strconv.ParseInt(header.Get("Ratelimit-Reset"), 10, 64)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limitErr == nil && limit > 0 {
+		l.limit = limit
+	}
+	if remainingErr == nil {
+		l.remaining = remaining
+	}
+	if resetErr == nil {
+		l.reset = time.Unix(reset, 0)
+	}
+}
+
+// exhaust empties the bucket after a 429 so queued requests wait for the reset
+func (l *rateLimiter) exhaust(header http.Header) {
+	l.update(header)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.remaining = 0
+}
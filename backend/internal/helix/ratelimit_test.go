@@ -0,0 +1,112 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func rateLimitHeader(limit, remaining int, reset time.Time) http.Header {
+	header := http.Header{}
+	header.Set("Ratelimit-Limit", strconv.Itoa(limit))
+	header.Set("Ratelimit-Remaining", strconv.Itoa(remaining))
+	header.Set("Ratelimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return header
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	l := newRateLimiter()
+	reset := time.Now().Add(time.Minute)
+
+	l.update(rateLimitHeader(120, 7, reset))
+	if l.limit != 120 || l.remaining != 7 || l.reset.Unix() != reset.Unix() {
+		t.Errorf("unexpected bucket: limit %d, remaining %d, reset %v", l.limit, l.remaining, l.reset)
+	}
+
+	// Responses without the headers leave the bucket alone
+	l.update(http.Header{})
+	if l.limit != 120 || l.remaining != 7 {
+		t.Errorf("unexpected bucket after empty headers: limit %d, remaining %d", l.limit, l.remaining)
+	}
+
+	l.exhaust(rateLimitHeader(120, 50, reset))
+	if l.remaining != 0 {
+		t.Errorf("expected exhaust to empty the bucket, %d left", l.remaining)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := newRateLimiter()
+	l.update(rateLimitHeader(800, 1, time.Now().Add(time.Hour)))
+
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("expected the last token, got %v", err)
+	}
+
+	// The bucket is empty until the reset an hour away
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+
+	// Once the reset passed the bucket is full again
+	l.update(rateLimitHeader(800, 0, time.Now().Add(-time.Second)))
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("expected a refilled bucket, got %v", err)
+	}
+	if l.remaining != 799 {
+		t.Errorf("expected 799 tokens after the refill, got %d", l.remaining)
+	}
+}
+
+func TestClientRetriesRateLimited(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		// The bucket resets right away so the retry doesn't wait
+		for key, values := range rateLimitHeader(800, 0, time.Now()) {
+			w.Header()[key] = values
+		}
+
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"error":"Too Many Requests","status":429,"message":"slow down"}`)
+			return
+		}
+		io.WriteString(w, `{"data":[{"id":"1234"}]}`)
+	})
+
+	id, err := client.GetUserID(context.Background(), "streamer")
+	if err != nil || id != "1234" {
+		t.Fatalf("expected 1234 after the retries, got %q (%v)", id, err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+}
+
+func TestClientRateLimitedWaitHonoursContext(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		for key, values := range rateLimitHeader(800, 0, time.Now().Add(time.Hour)) {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := client.Get(ctx, "/users", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the queued retry to give up with the context, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected a single request before the reset, got %d", n)
+	}
+}
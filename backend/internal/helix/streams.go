@@ -0,0 +1,57 @@
+package helix
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Stream is a live broadcast
+type Stream struct {
+	UserLogin   string    `json:"user_login"`
+	GameName    string    `json:"game_name"`
+	Title       string    `json:"title"`
+	ViewerCount int       `json:"viewer_count"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// ChannelInformation is what a channel shows while it is offline too
+type ChannelInformation struct {
+	BroadcasterID string `json:"broadcaster_id"`
+	GameName      string `json:"game_name"`
+	Title         string `json:"title"`
+}
+
+// GetStream returns the live stream of the channel with the given login, nil while it is offline
+func (c *Client) GetStream(ctx context.Context, login string) (*Stream, error) {
+	var response struct {
+		Data []Stream `json:"data"`
+	}
+
+	if err := c.Get(ctx, "/streams", url.Values{"user_login": {login}}, &response); err != nil {
+		return nil, err
+	}
+
+	if len(response.Data) == 0 {
+		return nil, nil
+	}
+
+	return &response.Data[0], nil
+}
+
+func (c *Client) GetChannelInformation(ctx context.Context, broadcasterID string) (ChannelInformation, error) {
+	var response struct {
+		Data []ChannelInformation `json:"data"`
+	}
+
+	if err := c.Get(ctx, "/channels", url.Values{"broadcaster_id": {broadcasterID}}, &response); err != nil {
+		return ChannelInformation{}, err
+	}
+
+	if len(response.Data) == 0 {
+		return ChannelInformation{}, fmt.Errorf("no channel found with id: %s", broadcasterID)
+	}
+
+	return response.Data[0], nil
+}
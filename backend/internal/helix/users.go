@@ -0,0 +1,93 @@
+package helix
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// GetUserID looks up the ID of the user with the given login
+func (c *Client) GetUserID(ctx context.Context, login string) (string, error) {
+	var response struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+
+	if err := c.Get(ctx, "/users", url.Values{"login": {login}}, &response); err != nil {
+		return "", err
+	}
+
+	if len(response.Data) == 0 {
+		return "", fmt.Errorf("no user found with login: %s", login)
+	}
+
+	return response.Data[0].ID, nil
+}
+
+// GetUserIDs looks up many users at once, 100 logins per request, so bulk
+// lookups cost a fraction of the rate limit bucket. The result maps login to ID.
+func (c *Client) GetUserIDs(ctx context.Context, logins []string) (map[string]string, error) {
+	ids := make(map[string]string, len(logins))
+
+	for start := 0; start < len(logins); start += 100 {
+		end := start + 100
+		if end > len(logins) {
+			end = len(logins)
+		}
+
+		var response struct {
+			Data []struct {
+				ID    string `json:"id"`
+				Login string `json:"login"`
+			} `json:"data"`
+		}
+
+		if err := c.Get(ctx, "/users", url.Values{"login": logins[start:end]}, &response); err != nil {
+			return nil, err
+		}
+
+		for _, user := range response.Data {
+			ids[user.Login] = user.ID
+		}
+	}
+
+	return ids, nil
+}
+
+// GetUserCreatedAt looks up when the account with the given ID was created
+func (c *Client) GetUserCreatedAt(ctx context.Context, userID string) (time.Time, error) {
+	var response struct {
+		Data []struct {
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"data"`
+	}
+
+	if err := c.Get(ctx, "/users", url.Values{"id": {userID}}, &response); err != nil {
+		return time.Time{}, err
+	}
+
+	if len(response.Data) == 0 {
+		return time.Time{}, fmt.Errorf("no user found with id: %s", userID)
+	}
+
+	return response.Data[0].CreatedAt, nil
+}
+
+// IsFollower tells whether the user follows the channel, the token needs the
+// moderator:read:followers scope and a moderator of the channel
+func (c *Client) IsFollower(ctx context.Context, broadcasterID, userID string) (bool, error) {
+	var response struct {
+		Data []struct {
+			UserID string `json:"user_id"`
+		} `json:"data"`
+	}
+
+	query := url.Values{"broadcaster_id": {broadcasterID}, "user_id": {userID}}
+	if err := c.Get(ctx, "/channels/followers", query, &response); err != nil {
+		return false, err
+	}
+
+	return len(response.Data) > 0, nil
+}
@@ -0,0 +1,77 @@
+package roles
+
+import "errors"
+
+var (
+	ErrUnknownRole = errors.New("unknown role")
+	ErrForbidden   = errors.New("role is not allowed to do this")
+)
+
+// Role is the permission level of a dashboard or chat user
+type Role string
+
+const (
+	// Viewer can only read dashboards like trends
+	Viewer Role = "viewer"
+	// Moderator can edit commands and run polls
+	Moderator Role = "moderator"
+	// Editor can also manage the stream and the channel
+	Editor Role = "editor"
+	// Owner can do everything, including credentials and user management
+	Owner Role = "owner"
+)
+
+var levels = map[Role]int{
+	Viewer:    1,
+	Moderator: 2,
+	Editor:    3,
+	Owner:     4,
+}
+
+// Actor is a dashboard or chat user carrying out an action
+type Actor struct {
+	Login string
+	Role  Role
+}
+
+// Require returns ErrForbidden unless the actor has at least the min role
+func (a Actor) Require(min Role) error {
+	if !a.Role.AtLeast(min) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Parse validates a role name
+func Parse(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := levels[role]; !ok {
+		return "", ErrUnknownRole
+	}
+	return role, nil
+}
+
+// AtLeast tells whether r grants everything min does
+func (r Role) AtLeast(min Role) bool {
+	return levels[r] >= levels[min]
+}
+
+// Max returns the higher of two roles
+func Max(a, b Role) Role {
+	if levels[a] >= levels[b] {
+		return a
+	}
+	return b
+}
+
+// FromBadges maps Twitch chat badges to a role
+func FromBadges(badges map[string]int) Role {
+	switch {
+	case badges["broadcaster"] > 0:
+		return Owner
+	case badges["moderator"] > 0:
+		return Moderator
+	default:
+		return Viewer
+	}
+}
@@ -0,0 +1,75 @@
+package roles
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFromBadges(t *testing.T) {
+	tests := []struct {
+		name   string
+		badges map[string]int
+		want   Role
+	}{
+		{"no badges", nil, Viewer},
+		{"subscriber", map[string]int{"subscriber": 12}, Viewer},
+		{"vip", map[string]int{"vip": 1}, Viewer},
+		{"moderator", map[string]int{"moderator": 1}, Moderator},
+		{"moderator and subscriber", map[string]int{"moderator": 1, "subscriber": 3}, Moderator},
+		{"broadcaster", map[string]int{"broadcaster": 1, "subscriber": 0}, Owner},
+		{"broadcaster badge version 0", map[string]int{"broadcaster": 0}, Viewer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromBadges(tt.badges); got != tt.want {
+				t.Errorf("FromBadges(%v) = %s, want %s", tt.badges, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	order := []Role{Viewer, Moderator, Editor, Owner}
+
+	for i, role := range order {
+		for j, min := range order {
+			if got := role.AtLeast(min); got != (i >= j) {
+				t.Errorf("%s.AtLeast(%s) = %v", role, min, got)
+			}
+		}
+	}
+
+	if Role("admin").AtLeast(Viewer) {
+		t.Error("an unknown role must not grant anything")
+	}
+}
+
+func TestMax(t *testing.T) {
+	if got := Max(Moderator, Editor); got != Editor {
+		t.Errorf("Max(moderator, editor) = %s", got)
+	}
+	if got := Max(Owner, Viewer); got != Owner {
+		t.Errorf("Max(owner, viewer) = %s", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if role, err := Parse("editor"); err != nil || role != Editor {
+		t.Errorf("Parse(editor) = %s, %v", role, err)
+	}
+	if _, err := Parse("Owner"); !errors.Is(err, ErrUnknownRole) {
+		t.Errorf("expected role names to be case sensitive, got %v", err)
+	}
+}
+
+func TestActorRequire(t *testing.T) {
+	actor := Actor{Login: "mod", Role: Moderator}
+
+	if err := actor.Require(Moderator); err != nil {
+		t.Errorf("expected a moderator to pass, got %v", err)
+	}
+	if err := actor.Require(Editor); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+}
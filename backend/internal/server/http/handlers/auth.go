@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+	"twitch-client/internal/server/middleware"
+)
+
+// HandleMe returns the dashboard user the request is signed in as
+func (h *Handlers) HandleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		h.sendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", user)
+}
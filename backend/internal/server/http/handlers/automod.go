@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"twitch-client/internal/automod"
+	"twitch-client/internal/client"
+	"twitch-client/internal/db/models"
+)
+
+// automodRuleRequest is the editable part of an automod rule
+type automodRuleRequest struct {
+	Channel         string                   `json:"channel"`
+	Name            string                   `json:"name"`
+	Enabled         bool                     `json:"enabled"`
+	Priority        int                      `json:"priority"`
+	Conditions      models.AutomodConditions `json:"conditions"`
+	Action          string                   `json:"action"`
+	DurationSeconds int                      `json:"duration_seconds"`
+	Reason          string                   `json:"reason"`
+}
+
+func (req automodRuleRequest) rule() models.AutomodRule {
+	return models.AutomodRule{
+		Channel:         client.NormalizeChannel(req.Channel),
+		Name:            req.Name,
+		Enabled:         req.Enabled,
+		Priority:        req.Priority,
+		Conditions:      req.Conditions,
+		Action:          req.Action,
+		DurationSeconds: req.DurationSeconds,
+		Reason:          req.Reason,
+	}
+}
+
+// isRuleError tells whether err is a validation error of an automod rule
+func isRuleError(err error) bool {
+	for _, target := range []error{
+		automod.ErrInvalidName,
+		automod.ErrInvalidAction,
+		automod.ErrInvalidDuration,
+		automod.ErrNoConditions,
+		automod.ErrInvalidPattern,
+		automod.ErrInvalidCapsRatio,
+		automod.ErrInvalidPhrase,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handlers) HandleAutomodRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	rules, err := h.service.GetAutomodRules()
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch automod rules: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", rules)
+}
+
+func (h *Handlers) HandleAddAutomodRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req automodRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	rule := req.rule()
+	err := h.service.AddAutomodRule(&rule)
+	if isRuleError(err) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to add automod rule: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusCreated, "Automod rule added successfully", rule)
+}
+
+func (h *Handlers) HandleUpdateAutomodRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		ID int `json:"id"`
+		automodRuleRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.ID == 0 {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Rule ID is required")
+		return
+	}
+
+	rule := req.rule()
+	rule.ID = req.ID
+	err := h.service.UpdateAutomodRule(&rule)
+	if isRuleError(err) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to update automod rule: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Automod rule updated successfully", rule)
+}
+
+func (h *Handlers) HandleDeleteAutomodRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	if err := h.service.DeleteAutomodRule(id); err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to delete automod rule: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Automod rule deleted successfully", nil)
+}
+
+// HandleTestAutomodRule tries a stored rule, given by id, or an unsaved rule on a sample message
+func (h *Handlers) HandleTestAutomodRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		ID     int                `json:"id"`
+		Rule   automodRuleRequest `json:"rule"`
+		Sample automod.Sample     `json:"sample"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	rule := req.Rule.rule()
+	if req.ID != 0 {
+		stored, err := h.service.GetAutomodRule(req.ID)
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusNotFound, "Automod rule not found")
+			return
+		}
+		rule = stored
+	}
+
+	result, err := h.service.TestAutomodRule(rule, req.Sample)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", result)
+}
+
+// phraseListRequest is the editable part of a phrase list
+type phraseListRequest struct {
+	Name    string   `json:"name"`
+	Phrases []string `json:"phrases"`
+}
+
+func (h *Handlers) HandlePhraseLists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	lists, err := h.service.GetPhraseLists()
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch phrase lists: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", lists)
+}
+
+func (h *Handlers) HandleAddPhraseList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req phraseListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	list := models.PhraseList{Name: req.Name, Phrases: req.Phrases}
+	err := h.service.SavePhraseList(&list)
+	if isRuleError(err) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to add phrase list: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusCreated, "Phrase list added successfully", list)
+}
+
+// HandleUpdatePhraseList renames a phrase list and replaces its phrases
+func (h *Handlers) HandleUpdatePhraseList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		ID int `json:"id"`
+		phraseListRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.ID == 0 {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Phrase list ID is required")
+		return
+	}
+
+	list := models.PhraseList{ID: req.ID, Name: req.Name, Phrases: req.Phrases}
+	err := h.service.SavePhraseList(&list)
+	if isRuleError(err) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to update phrase list: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Phrase list updated successfully", list)
+}
+
+func (h *Handlers) HandleDeletePhraseList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid phrase list ID")
+		return
+	}
+
+	if err := h.service.DeletePhraseList(id); err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to delete phrase list: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Phrase list deleted successfully", nil)
+}
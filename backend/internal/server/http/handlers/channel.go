@@ -1,8 +1,29 @@
 package handlers
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+	"twitch-client/internal/client"
+)
 
-func (h *Handlers) HandleChannelChange(w http.ResponseWriter, r *http.Request) {
+// channel returns the channel parameter of the request, it has to be a channel
+// the bot is in. The error response is sent when it isn't.
+func (h *Handlers) channel(w http.ResponseWriter, r *http.Request) (string, bool) {
+	channel := client.NormalizeChannel(r.FormValue("channel"))
+	if channel == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Channel name is required")
+		return "", false
+	}
+
+	if !h.service.HasChannel(channel) {
+		h.sendErrorResponse(w, http.StatusNotFound, "Channel not joined: "+channel)
+		return "", false
+	}
+
+	return channel, true
+}
+
+func (h *Handlers) HandleAddChannel(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
@@ -14,26 +35,48 @@ func (h *Handlers) HandleChannelChange(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.SetChannel(newChannel)
+	err := h.service.JoinChannel(newChannel)
+	if errors.Is(err, client.ErrInvalidChannel) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
-		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to connect to channel: "+err.Error())
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to join channel: "+err.Error())
 		return
 	}
 
-	h.sendSuccessResponse(w, http.StatusOK, "Channel changed successfully", nil)
+	h.sendSuccessResponse(w, http.StatusOK, "Channel added successfully", nil)
 }
 
-func (h *Handlers) HandleGetChannel(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) HandleRemoveChannel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.PartChannel(channel); err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to leave channel: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Channel removed successfully", nil)
+}
+
+func (h *Handlers) HandleGetChannels(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	channel := h.service.GetCurrentChannel()
 	data := struct {
-		Channel string `json:"channel"`
+		Channels []string `json:"channels"`
 	}{
-		Channel: channel,
+		Channels: h.service.GetChannels(),
 	}
 	h.sendSuccessResponse(w, http.StatusOK, "", data)
 }
@@ -50,7 +93,7 @@ func (h *Handlers) HandleGetBroadcasterID(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	id, err := h.service.GetBroadcasterID(username)
+	id, err := h.service.GetBroadcasterID(r.Context(), username)
 	if err != nil {
 		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to get broadcaster ID: "+err.Error())
 		return
@@ -64,3 +107,13 @@ func (h *Handlers) HandleGetBroadcasterID(w http.ResponseWriter, r *http.Request
 
 	h.sendSuccessResponse(w, http.StatusOK, "", data)
 }
+
+// HandleStatus reports the IRC connection, so the dashboard shows whether the bot is in chat
+func (h *Handlers) HandleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", h.service.GetStatus())
+}
@@ -1,6 +1,19 @@
 package handlers
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+	"twitch-client/internal/client"
+	"twitch-client/internal/db"
+	"twitch-client/internal/helix"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
 
 func (h *Handlers) HandleSendMessage(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -8,13 +21,18 @@ func (h *Handlers) HandleSendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
 	message := r.FormValue("message")
 	if message == "" {
 		h.sendErrorResponse(w, http.StatusBadRequest, "Message is required")
 		return
 	}
 
-	err := h.service.SendMessage(message)
+	err := h.service.SendMessage(channel, message)
 	if err != nil {
 		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to send message: "+err.Error())
 		return
@@ -22,3 +40,138 @@ func (h *Handlers) HandleSendMessage(w http.ResponseWriter, r *http.Request) {
 
 	h.sendSuccessResponse(w, http.StatusOK, "Message sent successfully", nil)
 }
+
+// HandleChatHistory returns stored chat messages, newest first. It can be
+// filtered by channel, user, user_id, a from/to time range (RFC 3339) and q
+// for text search.
+func (h *Handlers) HandleChatHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := db.ChatHistoryFilter{
+		// History is kept for channels the bot already left as well
+		Channel:  client.NormalizeChannel(query.Get("channel")),
+		UserID:   query.Get("user_id"),
+		Username: query.Get("user"),
+		Search:   query.Get("q"),
+		Limit:    defaultHistoryLimit,
+	}
+
+	for name, dest := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				h.sendErrorResponse(w, http.StatusBadRequest, "Invalid "+name+" time, expected RFC 3339")
+				return
+			}
+			*dest = parsed
+		}
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxHistoryLimit {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	messages, err := h.service.GetChatHistory(filter)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch chat history: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", messages)
+}
+
+// HandleChatSearch runs a full-text search over stored chat, optionally in a single channel
+func (h *Handlers) HandleChatSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	search := query.Get("q")
+	if search == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Search query is required")
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > maxHistoryLimit {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	messages, err := h.service.SearchChat(client.NormalizeChannel(query.Get("channel")), search, limit)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to search chat: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", messages)
+}
+
+// HandleChatSettings returns (GET) or changes (PATCH) the chat modes of a
+// channel. PATCH takes the Helix chat settings fields that should change and
+// an optional revert_after_seconds to switch them back later.
+func (h *Handlers) HandleChatSettings(w http.ResponseWriter, r *http.Request) {
+	var channel string
+	switch r.Method {
+	case http.MethodGet:
+		var ok bool
+		if channel, ok = h.channel(w, r); !ok {
+			return
+		}
+
+	case http.MethodPatch:
+		var req struct {
+			Channel            string `json:"channel"`
+			RevertAfterSeconds int    `json:"revert_after_seconds"`
+			helix.ChatSettingsUpdate
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		channel = client.NormalizeChannel(req.Channel)
+		if !h.service.HasChannel(channel) {
+			h.sendErrorResponse(w, http.StatusNotFound, "Channel not joined: "+channel)
+			return
+		}
+
+		if req.RevertAfterSeconds < 0 {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid revert_after_seconds")
+			return
+		}
+
+		revertAfter := time.Duration(req.RevertAfterSeconds) * time.Second
+		if err := h.service.UpdateChatSettings(r.Context(), channel, req.ChatSettingsUpdate, revertAfter, actor(r)); err != nil {
+			h.sendModerationError(w, "update chat settings", err)
+			return
+		}
+
+	default:
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	settings, err := h.service.GetChatSettings(r.Context(), channel)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadGateway, "Failed to fetch chat settings: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", settings)
+}
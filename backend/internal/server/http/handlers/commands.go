@@ -2,17 +2,70 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"twitch-client/internal/bot/template"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/service"
 )
 
+// commandRequest is the editable part of a command
+type commandRequest struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Enabled         bool     `json:"enabled"`
+	Response        string   `json:"response"`
+	Aliases         []string `json:"aliases"`
+	PermissionLevel string   `json:"permission_level"`
+	Cooldown        int      `json:"cooldown_seconds"`
+	CooldownMode    string   `json:"cooldown_mode"`
+	CooldownWarning *bool    `json:"cooldown_warning"`
+}
+
+func (req commandRequest) command() models.Command {
+	return models.Command{
+		Name:            req.Name,
+		Description:     req.Description,
+		Enabled:         req.Enabled,
+		Response:        req.Response,
+		Aliases:         req.Aliases,
+		PermissionLevel: req.PermissionLevel,
+		CooldownSeconds: req.Cooldown,
+		CooldownMode:    req.CooldownMode,
+		// Cooldown warnings stay on unless they are turned off
+		CooldownWarning: req.CooldownWarning == nil || *req.CooldownWarning,
+	}
+}
+
+// isCommandError tells whether err is a validation error of a command
+func isCommandError(err error) bool {
+	for _, target := range []error{
+		template.ErrInvalidTemplate,
+		service.ErrInvalidAlias,
+		service.ErrCommandTaken,
+		service.ErrInvalidPermissionLevel,
+		service.ErrInvalidCooldownMode,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *Handlers) HandleCommands(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	commands, err := h.service.GetAllCommands()
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	commands, err := h.service.GetAllCommands(channel)
 	if err != nil {
 		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch commands: "+err.Error())
 		return
@@ -27,14 +80,7 @@ func (h *Handlers) HandleAddCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Enabled     bool   `json:"enabled"`
-		Response    string `json:"response"`
-		Cooldown    int    `json:"cooldown_seconds"`
-	}
-
+	var req commandRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
 		return
@@ -45,7 +91,22 @@ func (h *Handlers) HandleAddCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.AddCommand(req.Name, req.Description, req.Response, req.Enabled, req.Cooldown)
+	// Commands added without a channel are shared by every channel
+	channel := ""
+	if r.URL.Query().Get("channel") != "" {
+		var ok bool
+		if channel, ok = h.channel(w, r); !ok {
+			return
+		}
+	}
+
+	command := req.command()
+	command.Channel = channel
+	err := h.service.AddCommand(&command)
+	if isCommandError(err) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to add command: "+err.Error())
 		return
@@ -88,14 +149,9 @@ func (h *Handlers) HandleUpdateCommand(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		ID          int    `json:"id"`
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Enabled     bool   `json:"enabled"`
-		Response    string `json:"response"`
-		Cooldown    int    `json:"cooldown_seconds"`
+		ID int `json:"id"`
+		commandRequest
 	}
-
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
 		return
@@ -106,11 +162,17 @@ func (h *Handlers) HandleUpdateCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	command, err := h.service.UpdateCommand(req.ID, req.Name, req.Description, req.Response, req.Enabled, req.Cooldown)
+	command := req.command()
+	command.ID = req.ID
+	updated, err := h.service.UpdateCommand(&command)
+	if isCommandError(err) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to update command: "+err.Error())
 		return
 	}
 
-	h.sendSuccessResponse(w, http.StatusOK, "Command updated successfully", command)
+	h.sendSuccessResponse(w, http.StatusOK, "Command updated successfully", updated)
 }
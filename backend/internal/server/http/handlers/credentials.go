@@ -3,12 +3,26 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"twitch-client/internal/credentials"
 )
 
+// HandleCredentials reports or replaces the credentials of the account given
+// in the role query parameter, the bot account is used when it is missing
 func (h *Handlers) HandleCredentials(w http.ResponseWriter, r *http.Request) {
+	role := credentials.RoleBot
+	if r.URL.Query().Get("role") != "" {
+		role = credentials.Role(r.URL.Query().Get("role"))
+	}
+
+	creds, err := h.accounts.For(role)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
-		client_id, oauth_token, err := h.credentials.Get()
+		client_id, oauth_token, err := creds.Get()
 		if err != nil {
 			h.sendErrorResponse(w, http.StatusOK, "not_set")
 			return
@@ -19,20 +33,29 @@ func (h *Handlers) HandleCredentials(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		h.sendSuccessResponse(w, http.StatusOK, "set", nil)
+		login, _ := creds.Identity()
+		data := struct {
+			Role  credentials.Role `json:"role"`
+			Login string           `json:"login"`
+		}{
+			Role:  role,
+			Login: login,
+		}
+
+		h.sendSuccessResponse(w, http.StatusOK, "set", data)
 		break
 	case http.MethodPut:
-		var creds struct {
+		var req struct {
 			ClientID   string `json:"client_id"`
 			OAuthToken string `json:"oauth_token"`
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
 
-		h.credentials.Set(creds.ClientID, creds.OAuthToken)
+		creds.Set(req.ClientID, req.OAuthToken)
 
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(Response{
@@ -4,23 +4,18 @@ import (
 	"twitch-client/internal/credentials"
 	"twitch-client/internal/service"
 	"twitch-client/internal/service/auth"
-	"twitch-client/internal/service/poll"
 )
 
 type Handlers struct {
 	service     *service.Service
-	pollService *poll.Service
-	credentials *credentials.Credentials
+	accounts    *credentials.Accounts
 	authService auth.AuthService
 }
 
-func New(s *service.Service, c *credentials.Credentials, a auth.AuthService) *Handlers {
-	ps := poll.NewService()
-
+func New(s *service.Service, accounts *credentials.Accounts, a auth.AuthService) *Handlers {
 	return &Handlers{
 		service:     s,
-		credentials: c,
+		accounts:    accounts,
 		authService: a,
-		pollService: &ps,
 	}
 }
@@ -7,7 +7,7 @@ import (
 
 func (h *Handlers) HandleIndex(w http.ResponseWriter, r *http.Request) {
 	// get credentials
-	client_id, oauth_token, err := h.credentials.Get()
+	client_id, oauth_token, err := h.accounts.Bot.Get()
 	if err != nil {
 		http.Error(w, "Failed to get credentials", http.StatusInternalServerError)
 		return
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"twitch-client/internal/automod"
+	"twitch-client/internal/client"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/roles"
+	"twitch-client/internal/service"
+)
+
+// HandleLinkProtection returns (GET) or replaces (PUT) the link protection
+// settings of a channel. The allowlist is managed through HandleAllowedDomain.
+func (h *Handlers) HandleLinkProtection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		channel, ok := h.channel(w, r)
+		if !ok {
+			return
+		}
+
+		settings, err := h.service.GetLinkProtection(channel)
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch link protection: "+err.Error())
+			return
+		}
+
+		h.sendSuccessResponse(w, http.StatusOK, "", settings)
+
+	case http.MethodPut:
+		var settings models.LinkProtection
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		settings.Channel = client.NormalizeChannel(settings.Channel)
+		if settings.Channel == "" {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Channel name is required")
+			return
+		}
+
+		err := h.service.SaveLinkProtection(&settings)
+		if errors.Is(err, automod.ErrInvalidAction) || errors.Is(err, automod.ErrInvalidDuration) {
+			h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to save link protection: "+err.Error())
+			return
+		}
+
+		h.sendSuccessResponse(w, http.StatusOK, "Link protection saved successfully", settings)
+
+	default:
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// HandleAllowedDomain adds (POST) or removes (DELETE) a domain from the
+// allowlist of a channel
+func (h *Handlers) HandleAllowedDomain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel := client.NormalizeChannel(r.FormValue("channel"))
+	if channel == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Channel name is required")
+		return
+	}
+
+	domain := r.FormValue("domain")
+	if domain == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Domain is required")
+		return
+	}
+
+	update, message := h.service.AllowDomain, "Domain allowed"
+	if r.Method == http.MethodDelete {
+		update, message = h.service.DisallowDomain, "Domain removed from the allowlist"
+	}
+
+	err := update(channel, domain)
+	if errors.Is(err, automod.ErrInvalidDomain) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to update allowlist: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, message, nil)
+}
+
+// HandlePermitUser lets the user given in the user parameter post links for duration seconds
+func (h *Handlers) HandlePermitUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	username := r.FormValue("user")
+	if username == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "User is required")
+		return
+	}
+
+	duration, err := strconv.Atoi(r.FormValue("duration"))
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid duration")
+		return
+	}
+
+	err = h.service.PermitUser(channel, username, duration, actor(r))
+	switch {
+	case err == nil:
+	case errors.Is(err, roles.ErrForbidden):
+		h.sendErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, service.ErrInvalidPermit):
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to permit user: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "User permitted", nil)
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"twitch-client/internal/client"
+	"twitch-client/internal/db"
+	"twitch-client/internal/roles"
+	"twitch-client/internal/server/middleware"
+	"twitch-client/internal/service"
+)
+
+// actor returns the dashboard user a request was made by
+func actor(r *http.Request) roles.Actor {
+	user, _ := middleware.UserFromContext(r.Context())
+	return roles.Actor{Login: user.Login, Role: user.Role}
+}
+
+// sendModerationError maps moderation errors to responses
+func (h *Handlers) sendModerationError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, roles.ErrForbidden):
+		h.sendErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidDuration):
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		h.sendErrorResponse(w, http.StatusBadGateway, "Failed to "+action+": "+err.Error())
+	}
+}
+
+// HandleBanUser bans the user given in the user parameter
+func (h *Handlers) HandleBanUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	username := r.FormValue("user")
+	if username == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "User is required")
+		return
+	}
+
+	if err := h.service.BanUser(r.Context(), channel, username, r.FormValue("reason"), actor(r)); err != nil {
+		h.sendModerationError(w, "ban user", err)
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "User banned", nil)
+}
+
+// HandleTimeoutUser times out the user given in the user parameter for duration seconds
+func (h *Handlers) HandleTimeoutUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	username := r.FormValue("user")
+	if username == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "User is required")
+		return
+	}
+
+	duration, err := strconv.Atoi(r.FormValue("duration"))
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid duration")
+		return
+	}
+
+	if err := h.service.TimeoutUser(r.Context(), channel, username, duration, r.FormValue("reason"), actor(r)); err != nil {
+		h.sendModerationError(w, "time out user", err)
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "User timed out", nil)
+}
+
+// HandleUnbanUser lifts a ban or timeout of the user given in the user parameter
+func (h *Handlers) HandleUnbanUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	username := r.FormValue("user")
+	if username == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "User is required")
+		return
+	}
+
+	if err := h.service.UnbanUser(r.Context(), channel, username, r.FormValue("reason"), actor(r)); err != nil {
+		h.sendModerationError(w, "unban user", err)
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "User unbanned", nil)
+}
+
+// HandleDeleteMessage deletes the chat message given in the message_id parameter
+func (h *Handlers) HandleDeleteMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	messageID := r.FormValue("message_id")
+	if messageID == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Message ID is required")
+		return
+	}
+
+	if err := h.service.DeleteMessage(r.Context(), channel, messageID, actor(r)); err != nil {
+		h.sendModerationError(w, "delete message", err)
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Message deleted", nil)
+}
+
+func (h *Handlers) HandleClearChat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ClearChat(r.Context(), channel, actor(r)); err != nil {
+		h.sendModerationError(w, "clear chat", err)
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Chat cleared", nil)
+}
+
+// HandleModerationLog returns the moderation audit log, newest first. It can be
+// filtered by channel, user, action and a from/to time range (RFC 3339).
+func (h *Handlers) HandleModerationLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := db.ModerationLogFilter{
+		Channel:  client.NormalizeChannel(query.Get("channel")),
+		Username: strings.ToLower(query.Get("user")),
+		Action:   query.Get("action"),
+		Limit:    defaultHistoryLimit,
+	}
+
+	for name, dest := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				h.sendErrorResponse(w, http.StatusBadRequest, "Invalid "+name+" time, expected RFC 3339")
+				return
+			}
+			*dest = parsed
+		}
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxHistoryLimit {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	actions, err := h.service.GetModerationLog(filter)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch moderation log: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", actions)
+}
+
+// HandleUndoModeration lifts a ban or timeout from the moderation log
+func (h *Handlers) HandleUndoModeration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	action, err := h.service.UndoModerationAction(r.Context(), req.ID, actor(r))
+	switch {
+	case err == nil:
+	case errors.Is(err, roles.ErrForbidden):
+		h.sendErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, service.ErrActionNotFound):
+		h.sendErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, service.ErrCannotUndo), errors.Is(err, service.ErrAlreadyUndone):
+		h.sendErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	default:
+		h.sendErrorResponse(w, http.StatusBadGateway, "Failed to undo moderation action: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Moderation action undone", action)
+}
@@ -1,13 +1,247 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/service/poll"
 )
 
+// pollService returns the poll service of the channel in the request
+func (h *Handlers) pollService(w http.ResponseWriter, r *http.Request) (poll.Service, bool) {
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	return h.service.Polls(channel), true
+}
+
 func (h *Handlers) HandlePollStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", polls.Results())
+}
+
+func (h *Handlers) HandleCreatePoll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Question string   `json:"question"`
+		Options  []string `json:"options"`
+		Duration int      `json:"duration_seconds"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.Duration < 0 {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Duration can't be negative")
+		return
+	}
+
+	err := polls.StartPoll(r.Context(), req.Question, req.Options, req.Duration)
+	switch err {
+	case nil:
+	case poll.ErrPollInProgress:
+		h.sendErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	case poll.ErrInvalidQuestion, poll.ErrNotEnoughOptions, poll.ErrInvalidDuration:
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to create poll: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusCreated, "Poll created successfully", polls.Results())
+}
+
+func (h *Handlers) HandleEndPoll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	results, err := polls.EndPoll(r.Context())
+	if err == poll.ErrPollNotStarted {
+		h.sendErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to end poll: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Poll ended successfully", results)
+}
+
+func (h *Handlers) HandleVote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Option   int    `json:"option"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.Username == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Username is required")
+		return
+	}
+
+	err := polls.HandleVote(req.Username, req.Option)
+	switch err {
+	case nil:
+	case poll.ErrPollNotStarted, poll.ErrNotSupported:
+		h.sendErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	case poll.ErrInvalidOption:
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to vote: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Vote registered", nil)
+}
+
+func (h *Handlers) HandlePollResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	results := polls.Results()
+	data := struct {
+		Question   string              `json:"question"`
+		Options    []models.PollOption `json:"options"`
+		TotalVotes int                 `json:"total_votes"`
+	}{
+		Question:   results.Question,
+		Options:    results.Options,
+		TotalVotes: results.TotalVotes,
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", data)
+}
+
+func (h *Handlers) HandlePollHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	history, err := polls.History(limit)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch poll history: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", history)
+}
+
+func (h *Handlers) HandlePollMode(w http.ResponseWriter, r *http.Request) {
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		data := struct {
+			Mode poll.Mode `json:"mode"`
+		}{
+			Mode: polls.Mode(),
+		}
+		h.sendSuccessResponse(w, http.StatusOK, "", data)
+	case http.MethodPut:
+		var req struct {
+			Mode                 poll.Mode `json:"mode"`
+			ChannelPointsVoting  bool      `json:"channel_points_voting"`
+			ChannelPointsPerVote int       `json:"channel_points_per_vote"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		err := polls.SetMode(req.Mode)
+		switch err {
+		case nil:
+		case poll.ErrInvalidMode:
+			h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		case poll.ErrPollInProgress:
+			h.sendErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		default:
+			h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to change poll mode: "+err.Error())
+			return
+		}
+
+		polls.SetChannelPoints(req.ChannelPointsVoting, req.ChannelPointsPerVote)
 
+		h.sendSuccessResponse(w, http.StatusOK, "Poll mode changed successfully", nil)
+	default:
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
 }
-func (h *Handlers) HandleCreatePoll(w http.ResponseWriter, r *http.Request)  {}
-func (h *Handlers) HandleEndPoll(w http.ResponseWriter, r *http.Request)     {}
-func (h *Handlers) HandleVote(w http.ResponseWriter, r *http.Request)        {}
-func (h *Handlers) HandlePollResults(w http.ResponseWriter, r *http.Request) {}
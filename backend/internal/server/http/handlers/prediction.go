@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"twitch-client/internal/service/poll"
+)
+
+func (h *Handlers) HandlePredictionStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	prediction, err := polls.Prediction(r.Context())
+	switch err {
+	case nil:
+	case poll.ErrNotSupported, poll.ErrPredictionNotStarted:
+		h.sendErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	default:
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch prediction: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", prediction)
+}
+
+func (h *Handlers) HandleCreatePrediction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Title    string   `json:"title"`
+		Outcomes []string `json:"outcomes"`
+		Window   int      `json:"prediction_window"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	err := polls.StartPrediction(r.Context(), req.Title, req.Outcomes, req.Window)
+	switch err {
+	case nil:
+	case poll.ErrNotSupported, poll.ErrPollInProgress:
+		h.sendErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	case poll.ErrInvalidQuestion, poll.ErrNotEnoughOptions, poll.ErrInvalidDuration:
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to create prediction: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusCreated, "Prediction created successfully", nil)
+}
+
+func (h *Handlers) HandleEndPrediction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	polls, ok := h.pollService(w, r)
+	if !ok {
+		return
+	}
+
+	// winning_outcome is the 1-based outcome number, 0 cancels the prediction
+	var req struct {
+		WinningOutcome int `json:"winning_outcome"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	prediction, err := polls.EndPrediction(r.Context(), req.WinningOutcome)
+	switch err {
+	case nil:
+	case poll.ErrNotSupported, poll.ErrPredictionNotStarted:
+		h.sendErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	case poll.ErrInvalidOption:
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to end prediction: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Prediction ended successfully", prediction)
+}
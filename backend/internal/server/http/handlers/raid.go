@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"twitch-client/internal/automod"
+	"twitch-client/internal/client"
+	"twitch-client/internal/db/models"
+)
+
+// HandleRaidProtection returns (GET) or replaces (PUT) the raid protection settings of a channel
+func (h *Handlers) HandleRaidProtection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		channel, ok := h.channel(w, r)
+		if !ok {
+			return
+		}
+
+		settings, err := h.service.GetRaidProtection(channel)
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch raid protection: "+err.Error())
+			return
+		}
+
+		h.sendSuccessResponse(w, http.StatusOK, "", settings)
+
+	case http.MethodPut:
+		var settings models.RaidProtection
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		settings.Channel = client.NormalizeChannel(settings.Channel)
+		if settings.Channel == "" {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Channel name is required")
+			return
+		}
+
+		err := h.service.SaveRaidProtection(&settings)
+		if errors.Is(err, automod.ErrInvalidRaidProtection) {
+			h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to save raid protection: "+err.Error())
+			return
+		}
+
+		h.sendSuccessResponse(w, http.StatusOK, "Raid protection saved successfully", settings)
+
+	default:
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// HandleRaidStatus returns the ongoing raid of a channel, data is null when there is none
+func (h *Handlers) HandleRaidStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	raid, ok := h.service.GetRaidStatus(channel)
+	if !ok {
+		h.sendSuccessResponse(w, http.StatusOK, "", nil)
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", raid)
+}
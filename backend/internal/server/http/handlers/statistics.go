@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"twitch-client/internal/client"
 	"twitch-client/internal/service"
 )
 
@@ -11,7 +12,12 @@ func (h *Handlers) HandleGetTrends(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	emotesResp, phrasesResp := h.service.GetTrends()
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	emotesResp, phrasesResp := h.service.GetTrends(channel)
 	data := struct {
 		Emotes  []service.EmoteResponse  `json:"emotes"`
 		Phrases []service.PhraseResponse `json:"phrases"`
@@ -29,6 +35,35 @@ func (h *Handlers) HandleGetTopUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	users := h.service.GetTopUsers()
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	users := h.service.GetTopUsers(channel)
 	h.sendSuccessResponse(w, http.StatusOK, "", users)
 }
+
+// HandleUserTimeline shows when a user was first seen, how much they wrote,
+// their recent messages and the bans or timeouts the bot issued
+func (h *Handlers) HandleUserTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	username := r.URL.Query().Get("user")
+	if username == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "User is required")
+		return
+	}
+
+	channel := client.NormalizeChannel(r.URL.Query().Get("channel"))
+	timeline, err := h.service.GetUserTimeline(r.Context(), username, channel)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to get user timeline: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", timeline)
+}
@@ -12,7 +12,7 @@ func (h *Handlers) HandleStreamInfo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	channel := r.URL.Query().Get("channel")
-	info, err := h.service.GetStreamInfo(channel)
+	info, err := h.service.GetStreamInfo(r.Context(), channel)
 	if err != nil {
 		h.sendErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -44,7 +44,7 @@ func (s *Handlers) HandleUpdateStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := s.service.UpdateStreamInfo(broadcasterID, req.Title, req.GameID, req.Tags)
+	err := s.service.UpdateStreamInfo(r.Context(), broadcasterID, req.Title, req.GameID, req.Tags)
 	if err != nil {
 		s.sendErrorResponse(w, http.StatusInternalServerError, "Failed to update stream info: "+err.Error())
 		return
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/timers"
+)
+
+// timerRequest is the editable part of a timer
+type timerRequest struct {
+	Name            string   `json:"name"`
+	Messages        []string `json:"messages"`
+	IntervalMinutes int      `json:"interval_minutes"`
+	MinLines        int      `json:"min_lines"`
+	LiveOnly        bool     `json:"live_only"`
+	Enabled         bool     `json:"enabled"`
+}
+
+func (req timerRequest) timer() models.Timer {
+	return models.Timer{
+		Name:            req.Name,
+		Messages:        req.Messages,
+		IntervalMinutes: req.IntervalMinutes,
+		MinLines:        req.MinLines,
+		LiveOnly:        req.LiveOnly,
+		Enabled:         req.Enabled,
+	}
+}
+
+func (h *Handlers) HandleTimers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	channelTimers, err := h.service.GetTimers(channel)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch timers: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "", channelTimers)
+}
+
+// HandleAddTimer adds a timer to the channel given in the channel query parameter
+func (h *Handlers) HandleAddTimer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channel, ok := h.channel(w, r)
+	if !ok {
+		return
+	}
+
+	var req timerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	timer := req.timer()
+	timer.Channel = channel
+	err := h.service.AddTimer(&timer)
+	if errors.Is(err, timers.ErrInvalidTimer) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to add timer: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusCreated, "Timer added successfully", timer)
+}
+
+func (h *Handlers) HandleUpdateTimer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		ID int `json:"id"`
+		timerRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.ID == 0 {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Timer ID is required")
+		return
+	}
+
+	timer := req.timer()
+	timer.ID = req.ID
+	err := h.service.UpdateTimer(&timer)
+	if errors.Is(err, timers.ErrInvalidTimer) {
+		h.sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to update timer: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Timer updated successfully", timer)
+}
+
+func (h *Handlers) HandleDeleteTimer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid timer ID")
+		return
+	}
+
+	if err := h.service.DeleteTimer(id); err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to delete timer: "+err.Error())
+		return
+	}
+
+	h.sendSuccessResponse(w, http.StatusOK, "Timer deleted successfully", nil)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"twitch-client/internal/roles"
+)
+
+// HandleDashboardUsers lists, grants and revokes dashboard roles
+func (h *Handlers) HandleDashboardUsers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		users, err := h.service.GetDashboardUsers()
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to fetch dashboard users: "+err.Error())
+			return
+		}
+
+		h.sendSuccessResponse(w, http.StatusOK, "", users)
+	case http.MethodPut:
+		var req struct {
+			Login string `json:"login"`
+			Role  string `json:"role"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		if req.Login == "" {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Login is required")
+			return
+		}
+
+		role, err := roles.Parse(req.Role)
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid role: "+req.Role)
+			return
+		}
+
+		user, err := h.service.SetDashboardRole(r.Context(), req.Login, role)
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to set role: "+err.Error())
+			return
+		}
+
+		h.sendSuccessResponse(w, http.StatusOK, "Role updated successfully", user)
+	case http.MethodDelete:
+		userID := r.URL.Query().Get("user_id")
+		if userID == "" {
+			h.sendErrorResponse(w, http.StatusBadRequest, "User ID is required")
+			return
+		}
+
+		if err := h.service.RemoveDashboardUser(userID); err != nil {
+			h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to remove dashboard user: "+err.Error())
+			return
+		}
+
+		h.sendSuccessResponse(w, http.StatusOK, "Dashboard user removed successfully", nil)
+	default:
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
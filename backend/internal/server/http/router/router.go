@@ -2,61 +2,120 @@ package router
 
 import (
 	"net/http"
+	"twitch-client/internal/roles"
 	"twitch-client/internal/server/http/handlers"
+	"twitch-client/internal/server/middleware"
 	"twitch-client/internal/service/auth"
 )
 
 type Router struct {
 	*handlers.Handlers
+	// public skips the dashboard session check, middleware requires it
+	public      func(http.HandlerFunc) http.HandlerFunc
 	middleware  func(http.HandlerFunc) http.HandlerFunc
 	authService auth.AuthService
 }
 
-func New(h *handlers.Handlers, mw func(http.HandlerFunc) http.HandlerFunc, as auth.AuthService) *Router {
+func New(h *handlers.Handlers, public, mw func(http.HandlerFunc) http.HandlerFunc, as auth.AuthService) *Router {
 	return &Router{
 		Handlers:    h,
+		public:      public,
 		middleware:  mw,
 		authService: as,
 	}
 }
 
+// require protects a handler with the dashboard session and the given minimal role
+func (r *Router) require(role roles.Role, handler http.HandlerFunc) http.HandlerFunc {
+	return r.middleware(middleware.RequireRole(role)(handler))
+}
+
 func (r *Router) RegisterRoutes() {
 	// Channel routes
-	http.HandleFunc("/api/channel/change", r.middleware(r.HandleChannelChange))
-	http.HandleFunc("/api/channel/get", r.middleware(r.HandleGetChannel))
-	http.HandleFunc("/api/channel/broadcaster_id", r.middleware(r.HandleGetBroadcasterID))
+	http.HandleFunc("/api/channel/add", r.require(roles.Editor, r.HandleAddChannel))
+	http.HandleFunc("/api/channel/remove", r.require(roles.Editor, r.HandleRemoveChannel))
+	http.HandleFunc("/api/channel/get", r.require(roles.Viewer, r.HandleGetChannels))
+	http.HandleFunc("/api/channel/broadcaster_id", r.require(roles.Viewer, r.HandleGetBroadcasterID))
+	http.HandleFunc("/api/status", r.require(roles.Viewer, r.HandleStatus))
 
 	// Chat routes
-	http.HandleFunc("/api/chat/send", r.middleware(r.HandleSendMessage))
+	http.HandleFunc("/api/chat/send", r.require(roles.Moderator, r.HandleSendMessage))
+	http.HandleFunc("/api/chat/history", r.require(roles.Moderator, r.HandleChatHistory))
+	http.HandleFunc("/api/chat/search", r.require(roles.Moderator, r.HandleChatSearch))
+	http.HandleFunc("/api/chat/settings", r.require(roles.Moderator, r.HandleChatSettings))
 
 	// Analytics routes
-	http.HandleFunc("/api/trends", r.middleware(r.HandleGetTrends))
-	http.HandleFunc("/api/users/top", r.middleware(r.HandleGetTopUsers))
+	http.HandleFunc("/api/trends", r.require(roles.Viewer, r.HandleGetTrends))
+	http.HandleFunc("/api/users/top", r.require(roles.Viewer, r.HandleGetTopUsers))
+	http.HandleFunc("/api/users/timeline", r.require(roles.Moderator, r.HandleUserTimeline))
 
 	// Stream management routes
-	http.HandleFunc("/api/stream/info", r.middleware(r.HandleStreamInfo))
-	http.HandleFunc("/api/stream/update", r.middleware(r.HandleUpdateStream))
+	http.HandleFunc("/api/stream/info", r.require(roles.Viewer, r.HandleStreamInfo))
+	http.HandleFunc("/api/stream/update", r.require(roles.Editor, r.HandleUpdateStream))
 
 	// Commands routes
-	http.HandleFunc("/api/commands", r.middleware(r.HandleCommands))
-	http.HandleFunc("/api/commands/add", r.middleware(r.HandleAddCommand))
-	http.HandleFunc("/api/commands/delete", r.middleware(r.HandleDeleteCommand))
-	http.HandleFunc("/api/commands/update", r.middleware(r.HandleUpdateCommand))
+	http.HandleFunc("/api/commands", r.require(roles.Viewer, r.HandleCommands))
+	http.HandleFunc("/api/commands/add", r.require(roles.Moderator, r.HandleAddCommand))
+	http.HandleFunc("/api/commands/delete", r.require(roles.Moderator, r.HandleDeleteCommand))
+	http.HandleFunc("/api/commands/update", r.require(roles.Moderator, r.HandleUpdateCommand))
+
+	// Timers routes
+	http.HandleFunc("/api/timers", r.require(roles.Moderator, r.HandleTimers))
+	http.HandleFunc("/api/timers/add", r.require(roles.Moderator, r.HandleAddTimer))
+	http.HandleFunc("/api/timers/update", r.require(roles.Moderator, r.HandleUpdateTimer))
+	http.HandleFunc("/api/timers/delete", r.require(roles.Moderator, r.HandleDeleteTimer))
+
+	// Automod routes
+	http.HandleFunc("/api/automod/rules", r.require(roles.Moderator, r.HandleAutomodRules))
+	http.HandleFunc("/api/automod/rules/add", r.require(roles.Moderator, r.HandleAddAutomodRule))
+	http.HandleFunc("/api/automod/rules/update", r.require(roles.Moderator, r.HandleUpdateAutomodRule))
+	http.HandleFunc("/api/automod/rules/delete", r.require(roles.Moderator, r.HandleDeleteAutomodRule))
+	http.HandleFunc("/api/automod/rules/test", r.require(roles.Moderator, r.HandleTestAutomodRule))
+	http.HandleFunc("/api/automod/phrases", r.require(roles.Moderator, r.HandlePhraseLists))
+	http.HandleFunc("/api/automod/phrases/add", r.require(roles.Moderator, r.HandleAddPhraseList))
+	http.HandleFunc("/api/automod/phrases/update", r.require(roles.Moderator, r.HandleUpdatePhraseList))
+	http.HandleFunc("/api/automod/phrases/delete", r.require(roles.Moderator, r.HandleDeletePhraseList))
+	http.HandleFunc("/api/automod/links", r.require(roles.Moderator, r.HandleLinkProtection))
+	http.HandleFunc("/api/automod/links/domains", r.require(roles.Moderator, r.HandleAllowedDomain))
+	http.HandleFunc("/api/automod/raid", r.require(roles.Moderator, r.HandleRaidProtection))
+	http.HandleFunc("/api/automod/raid/status", r.require(roles.Moderator, r.HandleRaidStatus))
+
+	// Moderation routes
+	http.HandleFunc("/api/moderation/ban", r.require(roles.Moderator, r.HandleBanUser))
+	http.HandleFunc("/api/moderation/timeout", r.require(roles.Moderator, r.HandleTimeoutUser))
+	http.HandleFunc("/api/moderation/unban", r.require(roles.Moderator, r.HandleUnbanUser))
+	http.HandleFunc("/api/moderation/delete", r.require(roles.Moderator, r.HandleDeleteMessage))
+	http.HandleFunc("/api/moderation/permit", r.require(roles.Moderator, r.HandlePermitUser))
+	http.HandleFunc("/api/moderation/clear", r.require(roles.Moderator, r.HandleClearChat))
+	http.HandleFunc("/api/moderation/log", r.require(roles.Moderator, r.HandleModerationLog))
+	http.HandleFunc("/api/moderation/undo", r.require(roles.Moderator, r.HandleUndoModeration))
 
 	// Auth routes
-	http.HandleFunc("/api/auth/login", r.middleware(r.authService.HandleLogin))
-	http.HandleFunc("/api/auth/callback", r.middleware(r.authService.HandleOAuth2Callback))
+	http.HandleFunc("/api/auth/login", r.public(r.authService.HandleLogin))
+	http.HandleFunc("/api/auth/callback", r.public(r.authService.HandleOAuth2Callback))
+	http.HandleFunc("/api/auth/logout", r.public(r.authService.HandleLogout))
+	http.HandleFunc("/api/auth/me", r.require(roles.Viewer, r.HandleMe))
 
 	// Poll routes
-	http.HandleFunc("/api/poll/status", r.middleware(r.HandlePollStatus))
-	http.HandleFunc("/api/poll/create", r.middleware(r.HandleCreatePoll))
-	http.HandleFunc("/api/poll/end", r.middleware(r.HandleEndPoll))
-	http.HandleFunc("/api/poll/vote", r.middleware(r.HandleVote))
-	http.HandleFunc("/api/poll/results", r.middleware(r.HandlePollResults))
+	http.HandleFunc("/api/poll/status", r.require(roles.Viewer, r.HandlePollStatus))
+	http.HandleFunc("/api/poll/create", r.require(roles.Moderator, r.HandleCreatePoll))
+	http.HandleFunc("/api/poll/end", r.require(roles.Moderator, r.HandleEndPoll))
+	http.HandleFunc("/api/poll/vote", r.require(roles.Moderator, r.HandleVote))
+	http.HandleFunc("/api/poll/results", r.require(roles.Viewer, r.HandlePollResults))
+	http.HandleFunc("/api/poll/history", r.require(roles.Viewer, r.HandlePollHistory))
+	http.HandleFunc("/api/poll/mode", r.require(roles.Moderator, r.HandlePollMode))
+
+	// Prediction routes
+	http.HandleFunc("/api/prediction/status", r.require(roles.Viewer, r.HandlePredictionStatus))
+	http.HandleFunc("/api/prediction/create", r.require(roles.Moderator, r.HandleCreatePrediction))
+	http.HandleFunc("/api/prediction/end", r.require(roles.Moderator, r.HandleEndPrediction))
 
 	// Credentials routes
-	http.HandleFunc("/api/credentials", r.middleware(r.HandleCredentials))
+	http.HandleFunc("/api/credentials", r.require(roles.Owner, r.HandleCredentials))
+
+	// Dashboard user routes
+	http.HandleFunc("/api/users/roles", r.require(roles.Owner, r.HandleDashboardUsers))
 
 	// Index route
-	http.HandleFunc("/", r.HandleIndex)
+	http.HandleFunc("/", r.require(roles.Owner, r.HandleIndex))
 }
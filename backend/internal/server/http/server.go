@@ -13,9 +13,9 @@ type Server struct {
 	router *router.Router
 }
 
-func NewServer(s *service.Service, c *credentials.Credentials, a auth.AuthService, mw func(http.HandlerFunc) http.HandlerFunc) *Server {
-	h := handlers.New(s, c, a)
-	r := router.New(h, mw, a)
+func NewServer(s *service.Service, accounts *credentials.Accounts, a auth.AuthService, public, mw func(http.HandlerFunc) http.HandlerFunc) *Server {
+	h := handlers.New(s, accounts, a)
+	r := router.New(h, public, mw, a)
 
 	return &Server{
 		router: r,
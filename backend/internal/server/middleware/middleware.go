@@ -10,12 +10,33 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"twitch-client/internal/roles"
 
 	"github.com/google/uuid"
 )
 
 type Middleware func(http.HandlerFunc) http.HandlerFunc
 
+// SessionCookieName is the cookie holding the dashboard session token
+const SessionCookieName = "dashboard-session"
+
+type contextKey string
+
+const userContextKey contextKey = "user"
+
+// User is the dashboard user a request was authenticated as
+type User struct {
+	ID    string     `json:"id"`
+	Login string     `json:"login"`
+	Role  roles.Role `json:"role"`
+}
+
+// UserFromContext returns the user stored by the Auth middleware
+func UserFromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userContextKey).(User)
+	return user, ok
+}
+
 // Chain applies middlewares in the order they are passed
 func Chain(handler http.HandlerFunc, middlewares ...Middleware) http.HandlerFunc {
 	for _, middleware := range middlewares {
@@ -98,8 +119,9 @@ func CORS(allowedOrigins []string) Middleware {
 
 			if allowed {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
 
 			// Handle preflight requests
@@ -128,11 +150,31 @@ func Recover() Middleware {
 	}
 }
 
-// Auth middleware for protecting routes
-func Auth(validateToken func(string) bool) Middleware {
+// Auth middleware for protecting routes. The token is read from the Authorization
+// header or the session cookie.
+func Auth(validateToken func(string) (User, bool)) Middleware {
+	return auth(validateToken, false)
+}
+
+// WebSocketAuth is Auth for the WebSocket handshake, browsers can't set headers
+// there so the token query parameter is accepted too. Anywhere else it would
+// leak sessions into access logs and Referer headers.
+func WebSocketAuth(validateToken func(string) (User, bool)) Middleware {
+	return auth(validateToken, true)
+}
+
+func auth(validateToken func(string) (User, bool), queryToken bool) Middleware {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			token := r.Header.Get("Authorization")
+			if token == "" {
+				if cookie, err := r.Cookie(SessionCookieName); err == nil {
+					token = cookie.Value
+				}
+			}
+			if token == "" && queryToken {
+				token = r.URL.Query().Get("token")
+			}
 			if token == "" {
 				http.Error(w, "Unauthorized: No token provided", http.StatusUnauthorized)
 				return
@@ -141,11 +183,33 @@ func Auth(validateToken func(string) bool) Middleware {
 			// Remove "Bearer " prefix if present
 			token = strings.TrimPrefix(token, "Bearer ")
 
-			if !validateToken(token) {
+			user, ok := validateToken(token)
+			if !ok {
 				http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
 				return
 			}
 
+			ctx := context.WithValue(r.Context(), userContextKey, user)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// RequireRole rejects requests of users below the given role, it has to run after Auth
+func RequireRole(min roles.Role) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			user, ok := UserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized: No user", http.StatusUnauthorized)
+				return
+			}
+
+			if !user.Role.AtLeast(min) {
+				http.Error(w, "Forbidden: "+string(min)+" role required", http.StatusForbidden)
+				return
+			}
+
 			next(w, r)
 		}
 	}
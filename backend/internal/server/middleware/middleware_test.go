@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"twitch-client/internal/roles"
+)
+
+func TestAuthTokenSources(t *testing.T) {
+	validate := func(token string) (User, bool) {
+		return User{Login: "owner", Role: roles.Owner}, token == "session"
+	}
+
+	tests := []struct {
+		name       string
+		middleware Middleware
+		request    func(r *http.Request)
+		want       int
+	}{
+		{"header", Auth(validate), func(r *http.Request) { r.Header.Set("Authorization", "Bearer session") }, http.StatusOK},
+		{"cookie", Auth(validate), func(r *http.Request) { r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "session"}) }, http.StatusOK},
+		{"query on api", Auth(validate), func(r *http.Request) { r.URL.RawQuery = "token=session" }, http.StatusUnauthorized},
+		{"query on websocket", WebSocketAuth(validate), func(r *http.Request) { r.URL.RawQuery = "token=session" }, http.StatusOK},
+		{"invalid token", WebSocketAuth(validate), func(r *http.Request) { r.URL.RawQuery = "token=forged" }, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tt.middleware(func(w http.ResponseWriter, r *http.Request) {
+				if user, ok := UserFromContext(r.Context()); !ok || user.Login != "owner" {
+					t.Error("expected the user in the request context")
+				}
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+			tt.request(r)
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 	"twitch-client/internal/config"
 	"twitch-client/internal/credentials"
+	"twitch-client/internal/db"
 	http_server "twitch-client/internal/server/http"
 	"twitch-client/internal/server/middleware"
 	socket "twitch-client/internal/server/websocket"
@@ -16,37 +17,54 @@ import (
 type Server struct {
 	service      *service.Service
 	socket       *socket.WebSocket
-	creds        *credentials.Credentials
+	accounts     *credentials.Accounts
 	config       *config.Config
+	db           *db.Database
 	cookieSecret []byte
 }
 
-func NewServer(svc *service.Service, ws *socket.WebSocket, c *credentials.Credentials, config *config.Config) *Server {
+func NewServer(svc *service.Service, ws *socket.WebSocket, accounts *credentials.Accounts, config *config.Config, db *db.Database) *Server {
 
 	return &Server{
-		service: svc,
-		socket:  ws,
-		creds:   c,
-		config:  config,
+		service:  svc,
+		socket:   ws,
+		accounts: accounts,
+		config:   config,
+		db:       db,
 	}
 }
 
 func (s *Server) Run() {
-	auth := auth.NewAuthService(s.creds, s.config)
+	auth := auth.NewAuthService(s.accounts, s.config, s.db)
 
 	// WebSocket route without most middlewares since it needs different handling
 	wsHandler := middleware.Chain(
 		s.socket.ServeWs,
+		middleware.WebSocketAuth(auth.ValidateSession),
 		middleware.Logger(),
 		middleware.WebSocketCORS(),
 	)
 
 	http.HandleFunc("/ws", wsHandler)
 
+	// Middleware chain for routes that are reachable without a dashboard session
+	publicMiddleware := func(handler http.HandlerFunc) http.HandlerFunc {
+		return middleware.Chain(
+			handler,
+			middleware.RequestID(),
+			middleware.Logger(),
+			middleware.Recover(),
+			middleware.CORS([]string{"http://localhost:3000"}),
+			middleware.RateLimit(100, time.Minute),
+			middleware.Compress(),
+		)
+	}
+
 	// Create your API middleware chain
 	apiMiddleware := func(handler http.HandlerFunc) http.HandlerFunc {
 		return middleware.Chain(
 			handler,
+			middleware.Auth(auth.ValidateSession),
 			middleware.RequestID(),
 			middleware.Logger(),
 			middleware.Recover(),
@@ -56,7 +74,7 @@ func (s *Server) Run() {
 		)
 	}
 
-	server := http_server.NewServer(s.service, s.creds, auth, apiMiddleware)
+	server := http_server.NewServer(s.service, s.accounts, auth, publicMiddleware, apiMiddleware)
 
 	// Modify your server to accept the middleware
 	if err := server.Start(); err != nil {
@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	// "regexp"
+	"strings"
 	"sync"
 	"time"
 	"twitch-client/internal/server/websocket/ratelimiter"
@@ -19,11 +20,26 @@ const (
 	UserJoinEvent Event = "user_join"
 	UserPartEvent Event = "user_part"
 	MessageEvent  Event = "message"
+
+	PollStartEvent  Event = "poll_start"
+	PollUpdateEvent Event = "poll_update"
+	PollEndEvent    Event = "poll_end"
+
+	PredictionStartEvent Event = "prediction_start"
+	PredictionEndEvent   Event = "prediction_end"
+
+	RaidStartEvent Event = "raid_start"
+	RaidEndEvent   Event = "raid_end"
+
+	ConnectionStateEvent Event = "connection_state"
+
+	ChatSettingsRevertFailedEvent Event = "chat_settings_revert_failed"
 )
 
 // Message represents a message with a timestamp, username, and content.
 type Message struct {
 	Type      Event       `json:"type"`
+	Channel   string      `json:"channel,omitempty"`
 	Timestamp time.Time   `json:"timestamp"`
 	Data      interface{} `json:"data"`
 }
@@ -43,6 +59,14 @@ type Client struct {
 	socket *WebSocket
 	conn   *websocket.Conn
 	send   chan []byte
+	// channel the client listens to, empty for every channel
+	channel string
+}
+
+// broadcastMessage is a message for the clients of a channel, an empty channel reaches everyone
+type broadcastMessage struct {
+	channel string
+	data    []byte
 }
 
 // WebSocket maintains the set of active clients and broadcasts messages.
@@ -51,7 +75,7 @@ type WebSocket struct {
 	clients map[*Client]bool
 
 	// Channel for incoming broadcast messages.
-	broadcast chan []byte
+	broadcast chan broadcastMessage
 
 	// Channel for registering new clients.
 	register chan *Client
@@ -69,7 +93,7 @@ type WebSocket struct {
 func NewWebSocket(rl *ratelimiter.RateLimiter) *WebSocket {
 	return &WebSocket{
 		clients:     make(map[*Client]bool),
-		broadcast:   make(chan []byte),
+		broadcast:   make(chan broadcastMessage),
 		register:    make(chan *Client),
 		unregister:  make(chan *Client),
 		Ratelimiter: *rl,
@@ -94,8 +118,12 @@ func (ws *WebSocket) Run() {
 		case message := <-ws.broadcast:
 			ws.mu.RLock()
 			for client := range ws.clients {
+				if message.channel != "" && client.channel != "" && client.channel != message.channel {
+					continue
+				}
+
 				select {
-				case client.send <- message:
+				case client.send <- message.data:
 					// message sent
 				default:
 					// If client's send buffer is full, remove the client.
@@ -110,12 +138,18 @@ func (ws *WebSocket) Run() {
 
 // BroadcastMessage sends a raw message (as a byte slice) to all connected clients.
 func (ws *WebSocket) BroadcastMessage(message []byte) {
-	ws.broadcast <- message
+	ws.broadcast <- broadcastMessage{data: message}
 }
 
-func (ws *WebSocket) BroadcastUserPartMessage(username string) {
+// BroadcastChannelMessage sends a raw message to the clients listening to a channel.
+func (ws *WebSocket) BroadcastChannelMessage(channel string, message []byte) {
+	ws.broadcast <- broadcastMessage{channel: channel, data: message}
+}
+
+func (ws *WebSocket) BroadcastUserPartMessage(channel, username string) {
 	msg := Message{
 		Type:      UserPartEvent,
+		Channel:   channel,
 		Timestamp: time.Now(),
 		Data: UserJoinMessage{
 			Username: username,
@@ -128,12 +162,13 @@ func (ws *WebSocket) BroadcastUserPartMessage(username string) {
 		return
 	}
 
-	ws.BroadcastMessage(data)
+	ws.BroadcastChannelMessage(channel, data)
 }
 
-func (ws *WebSocket) BroadcastUserJoinMessage(username string) {
+func (ws *WebSocket) BroadcastUserJoinMessage(channel, username string) {
 	msg := Message{
 		Type:      UserJoinEvent,
+		Channel:   channel,
 		Timestamp: time.Now(),
 		Data: UserJoinMessage{
 			Username: username,
@@ -146,12 +181,48 @@ func (ws *WebSocket) BroadcastUserJoinMessage(username string) {
 		return
 	}
 
+	ws.BroadcastChannelMessage(channel, data)
+}
+
+// BroadcastChannelEvent sends an event to the dashboards of a channel, data is
+// its payload like the state of a poll or a raid
+func (ws *WebSocket) BroadcastChannelEvent(channel string, event Event, data interface{}) {
+	msg := Message{
+		Type:      event,
+		Channel:   channel,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
+	ws.BroadcastChannelMessage(channel, encoded)
+}
+
+// BroadcastConnectionMessage tells every dashboard about a change of the IRC connection
+func (ws *WebSocket) BroadcastConnectionMessage(status interface{}) {
+	msg := Message{
+		Type:      ConnectionStateEvent,
+		Timestamp: time.Now(),
+		Data:      status,
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
 	ws.BroadcastMessage(data)
 }
 
 // BroadcastUserMessage creates a Message with the current timestamp, username, and content,
 // marshals it into JSON, and broadcasts it to all connected clients.
-func (ws *WebSocket) BroadcastUserMessage(username, color, content string) {
+func (ws *WebSocket) BroadcastUserMessage(channel, username, color, content string) {
 	if !ws.Ratelimiter.IsAllowed(username) {
 		log.Printf("User %s is sending messages too quickly", username)
 		return
@@ -164,6 +235,7 @@ func (ws *WebSocket) BroadcastUserMessage(username, color, content string) {
 	msg := Message{
 		Timestamp: time.Now(),
 		Type:      MessageEvent,
+		Channel:   channel,
 		Data: UserMessage{
 			Username: username,
 			Color:    color,
@@ -177,7 +249,7 @@ func (ws *WebSocket) BroadcastUserMessage(username, color, content string) {
 		return
 	}
 
-	ws.BroadcastMessage(data)
+	ws.BroadcastChannelMessage(channel, data)
 }
 
 // upgrader is used to upgrade an HTTP connection to a WebSocket connection.
@@ -189,6 +261,7 @@ var upgrader = websocket.Upgrader{
 }
 
 // ServeWs upgrades the HTTP server connection to the WebSocket protocol and registers the client.
+// The channel query parameter limits the events to a single channel.
 func (ws *WebSocket) ServeWs(w http.ResponseWriter, r *http.Request) {
 	log.Println("WebSocket connection requested.")
 	conn, err := upgrader.Upgrade(w, r, nil)
@@ -200,6 +273,8 @@ func (ws *WebSocket) ServeWs(w http.ResponseWriter, r *http.Request) {
 		socket: ws,
 		conn:   conn,
 		send:   make(chan []byte, 256),
+		// Twitch channel names are lowercase
+		channel: strings.ToLower(strings.TrimPrefix(r.URL.Query().Get("channel"), "#")),
 	}
 	ws.register <- client
 
@@ -246,7 +321,7 @@ func (c *Client) readPump() {
 		}
 		// Optionally, process or log the message from the client.
 		// For example, you might choose to broadcast messages received from one client:
-		c.socket.BroadcastMessage(message)
+		c.socket.BroadcastChannelMessage(c.channel, message)
 	}
 }
 
@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"twitch-client/internal/config"
 	"twitch-client/internal/credentials"
+	"twitch-client/internal/db"
+	"twitch-client/internal/server/middleware"
 
 	"github.com/gorilla/sessions"
 	"golang.org/x/oauth2"
@@ -19,44 +21,111 @@ import (
 
 const (
 	stateCallbackKey = "oauth-state-callback"
+	roleCallbackKey  = "oauth-role-callback"
 	oauthSessionName = "oauth-session"
 	oauthTokenKey    = "oauth-token"
 )
 
+// scopes requested for every account role
+var scopes = map[credentials.Role][]string{
+	credentials.RoleBot: {
+		"chat:edit",
+		"chat:read",
+		"user:bot",
+		"user:read:chat",
+		"user:write:chat",
+		"whispers:read",
+		"channel:moderate",
+		"moderator:manage:banned_users",
+		"moderator:manage:chat_messages",
+		"moderator:manage:chat_settings",
+		"moderator:read:followers",
+	},
+	credentials.RoleBroadcaster: {
+		"channel:bot",
+		"channel:manage:broadcast",
+		"channel:manage:polls",
+		"channel:manage:predictions",
+		"channel:manage:moderators",
+	},
+	roleDashboard: {},
+}
+
 type authService struct {
-	credentials  *credentials.Credentials
-	oauth2Config *oauth2.Config
-	config       *config.Config
-	cookieStore  *sessions.CookieStore
+	accounts      *credentials.Accounts
+	oauth2Configs map[credentials.Role]*oauth2.Config
+	config        *config.Config
+	db            *db.Database
+	cookieStore   *sessions.CookieStore
 }
 
 type AuthService interface {
 	HandleLogin(w http.ResponseWriter, r *http.Request)
 	HandleOAuth2Callback(w http.ResponseWriter, r *http.Request)
+	HandleLogout(w http.ResponseWriter, r *http.Request)
+	ValidateSession(token string) (middleware.User, bool)
 }
 
-func NewAuthService(credentials *credentials.Credentials, config *config.Config) AuthService {
+func NewAuthService(accounts *credentials.Accounts, config *config.Config, db *db.Database) AuthService {
 	cookieSecret := []byte("super-secret")
 
 	gob.Register(&oauth2.Token{})
 
 	cookieStore := sessions.NewCookieStore(cookieSecret)
 
-	return &authService{
-		credentials: credentials,
-		oauth2Config: &oauth2.Config{
+	a := &authService{
+		accounts:      accounts,
+		oauth2Configs: make(map[credentials.Role]*oauth2.Config),
+		config:        config,
+		db:            db,
+		cookieStore:   cookieStore,
+	}
+
+	for _, role := range append(credentials.Roles(), roleDashboard) {
+		a.oauth2Configs[role] = &oauth2.Config{
 			ClientID:     config.TwitchClientID,
 			ClientSecret: config.TwitchClientSecret,
-			Scopes:       []string{"channel:bot", "channel:manage:broadcast", "channel:moderate", "chat:edit", "chat:read", "whispers:read", "moderator:manage:banned_users"},
+			Scopes:       scopes[role],
 			Endpoint:     twitch.Endpoint,
 			RedirectURL:  "http://localhost:42069/api/auth/callback",
-		},
-		config:      config,
-		cookieStore: cookieStore,
+		}
+	}
+
+	// Seed the credentials with the tokens from the environment unless some were stored
+	seed := map[credentials.Role]*oauth2.Token{
+		credentials.RoleBot:         {AccessToken: config.TwitchOAuthToken, RefreshToken: config.TwitchRefreshToken},
+		credentials.RoleBroadcaster: {AccessToken: config.TwitchBroadcasterOAuthToken, RefreshToken: config.TwitchBroadcasterRefreshToken},
+	}
+
+	for _, role := range credentials.Roles() {
+		creds, _ := accounts.For(role)
+		if _, _, err := creds.Get(); err != nil && seed[role].AccessToken != "" {
+			creds.SetToken(config.TwitchClientID, seed[role])
+		}
+
+		go a.maintainToken(role)
 	}
+
+	go a.cleanupSessions()
+
+	return a
 }
 
+// HandleLogin starts the OAuth flow for the account given in the role query
+// parameter, the bot account is used when it is missing. The dashboard role
+// only signs the user in to the dashboard.
 func (a *authService) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	role := credentials.RoleBot
+	if r.URL.Query().Get("role") != "" {
+		role = credentials.Role(r.URL.Query().Get("role"))
+	}
+
+	oauth2Config, ok := a.oauth2Configs[role]
+	if !ok {
+		http.Error(w, "Unknown account role", http.StatusBadRequest)
+		return
+	}
+
 	session, _ := a.cookieStore.Get(r, oauthSessionName)
 
 	var tokenBytes [16]byte
@@ -68,9 +137,10 @@ func (a *authService) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	state := hex.EncodeToString(tokenBytes[:])
 
 	session.AddFlash(state, stateCallbackKey)
+	session.AddFlash(string(role), roleCallbackKey)
 	session.Save(r, w)
 
-	http.Redirect(w, r, a.oauth2Config.AuthCodeURL(state), http.StatusTemporaryRedirect)
+	http.Redirect(w, r, oauth2Config.AuthCodeURL(state), http.StatusTemporaryRedirect)
 }
 
 func (a *authService) HandleOAuth2Callback(w http.ResponseWriter, r *http.Request) {
@@ -101,15 +171,60 @@ func (a *authService) HandleOAuth2Callback(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	token, err := a.oauth2Config.Exchange(context.Background(), r.FormValue("code"))
+	role := credentials.RoleBot
+	if roles := session.Flashes(roleCallbackKey); len(roles) > 0 {
+		if r, ok := roles[0].(string); ok {
+			role = credentials.Role(r)
+		}
+	}
+
+	oauth2Config, ok := a.oauth2Configs[role]
+	if !ok {
+		http.Error(w, "Unknown account role", http.StatusBadRequest)
+		return
+	}
+
+	token, err := oauth2Config.Exchange(context.Background(), r.FormValue("code"))
 	if err != nil {
 		return
 	}
 
-	session.Values[oauthTokenKey] = token
-	session.Save(r, w)
+	info, err := validate(token.AccessToken)
+	if err != nil {
+		log.Printf("Failed to validate %s oauth token: %v", role, err)
+		http.Error(w, "Couldn't verify your Twitch account, please try again.", http.StatusBadGateway)
+		return
+	}
+
+	if role != roleDashboard {
+		creds, err := a.accounts.For(role)
+		if err != nil {
+			http.Error(w, "Unknown account role", http.StatusBadRequest)
+			return
+		}
 
-	a.credentials.Set(a.config.TwitchClientID, token.AccessToken)
+		if !a.mayReplace(r, creds, info.Login) {
+			http.Error(w, "Only a dashboard owner can replace the "+string(role)+" account.", http.StatusForbidden)
+			return
+		}
+
+		session.Values[oauthTokenKey] = token
+		session.Save(r, w)
+
+		creds.SetToken(a.config.TwitchClientID, token)
+		creds.SetValidation(info.Login, info.UserID, token.Expiry)
+	}
+
+	if _, ok := a.resolveRole(info.UserID, info.Login); !ok {
+		http.Error(w, "Your Twitch account isn't allowed to use this dashboard.", http.StatusForbidden)
+		return
+	}
+
+	if err := a.openSession(w, r, info.UserID, info.Login); err != nil {
+		log.Printf("Failed to open dashboard session: %v", err)
+		http.Error(w, "Couldn't sign you in, please try again.", http.StatusInternalServerError)
+		return
+	}
 
 	// Redirect the user back to your application's homepage or a success page
 	http.Redirect(w, r, "http://localhost:3000/", http.StatusTemporaryRedirect)
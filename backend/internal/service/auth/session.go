@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"twitch-client/internal/credentials"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/roles"
+	"twitch-client/internal/server/middleware"
+)
+
+const (
+	sessionTTL = 7 * 24 * time.Hour
+
+	// roleDashboard only proves who the user is to open a dashboard session,
+	// its token is thrown away afterwards
+	roleDashboard credentials.Role = "dashboard"
+)
+
+// ValidateSession returns the dashboard user a session token belongs to
+func (a *authService) ValidateSession(token string) (middleware.User, bool) {
+	session, err := a.db.GetSession(hashToken(token))
+	if err != nil {
+		return middleware.User{}, false
+	}
+
+	// The allow list and the roles may have changed since the session was opened
+	role, ok := a.resolveRole(session.UserID, session.Login)
+	if !ok {
+		return middleware.User{}, false
+	}
+
+	return middleware.User{ID: session.UserID, Login: session.Login, Role: role}, true
+}
+
+// HandleLogout closes the dashboard session of the request
+func (a *authService) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if cookie, err := r.Cookie(middleware.SessionCookieName); err == nil {
+		if err := a.db.DeleteSession(hashToken(cookie.Value)); err != nil {
+			log.Printf("Failed to delete session: %v", err)
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// openSession creates a dashboard session for the user and hands it out as a cookie
+func (a *authService) openSession(w http.ResponseWriter, r *http.Request, userID, login string) error {
+	var tokenBytes [32]byte
+	if _, err := rand.Read(tokenBytes[:]); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(tokenBytes[:])
+
+	session := &models.Session{
+		TokenHash: hashToken(token),
+		UserID:    userID,
+		Login:     login,
+		ExpiresAt: time.Now().Add(sessionTTL),
+	}
+	if err := a.db.CreateSession(session); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+// currentUser returns the user of the request's session cookie, if any
+func (a *authService) currentUser(r *http.Request) (middleware.User, bool) {
+	cookie, err := r.Cookie(middleware.SessionCookieName)
+	if err != nil {
+		return middleware.User{}, false
+	}
+	return a.ValidateSession(cookie.Value)
+}
+
+// resolveRole tells whether a Twitch user may use the dashboard and with which
+// role. Users with a stored role get it, the configured owners and the bot and
+// the broadcaster accounts are owners and everyone else on the allow list is a
+// viewer.
+func (a *authService) resolveRole(userID, login string) (roles.Role, bool) {
+	login = strings.ToLower(login)
+	if login == "" {
+		return "", false
+	}
+
+	if user, err := a.db.GetDashboardUser(userID); err == nil {
+		return user.Role, true
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Failed to get dashboard user %s: %v", login, err)
+	}
+
+	if a.configuredOwner(login) {
+		return roles.Owner, true
+	}
+
+	for _, role := range credentials.Roles() {
+		creds, _ := a.accounts.For(role)
+		if accountLogin, _ := creds.Identity(); strings.ToLower(accountLogin) == login {
+			return roles.Owner, true
+		}
+	}
+
+	for _, allowed := range a.config.DashboardUsers {
+		if strings.ToLower(allowed) == login {
+			return roles.Viewer, true
+		}
+	}
+
+	return "", false
+}
+
+// mayReplace tells whether the request may store a token of login in creds.
+// The accounts in the slots are owners themselves, so only an owner session
+// can replace one. Without it an empty slot only takes a configured owner and
+// a filled one only a re-login of the same account.
+func (a *authService) mayReplace(r *http.Request, creds *credentials.Credentials, login string) bool {
+	if user, ok := a.currentUser(r); ok && user.Role.AtLeast(roles.Owner) {
+		return true
+	}
+
+	if _, _, err := creds.Get(); err != nil {
+		return a.configuredOwner(login)
+	}
+
+	current, _ := creds.Identity()
+	return current != "" && strings.EqualFold(current, login)
+}
+
+// configuredOwner tells whether login is one of the owners listed in the config
+func (a *authService) configuredOwner(login string) bool {
+	for _, owner := range a.config.DashboardOwners {
+		if strings.EqualFold(owner, login) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *authService) cleanupSessions() {
+	for range time.Tick(time.Hour) {
+		if err := a.db.DeleteExpiredSessions(); err != nil {
+			log.Printf("Failed to delete expired sessions: %v", err)
+		}
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+	"twitch-client/internal/config"
+	"twitch-client/internal/credentials"
+)
+
+func TestMayReplace(t *testing.T) {
+	a := &authService{config: &config.Config{DashboardOwners: []string{"Owner"}}}
+
+	empty := credentials.NewCredentialsManager()
+	filled := credentials.NewCredentialsManager()
+	filled.Set("client-id", "token")
+	filled.SetValidation("streamer", "1", time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name  string
+		creds *credentials.Credentials
+		login string
+		want  bool
+	}{
+		{"stranger into an empty slot", empty, "stranger", false},
+		{"configured owner into an empty slot", empty, "owner", true},
+		{"re-login of the same account", filled, "Streamer", true},
+		{"stranger over a filled slot", filled, "stranger", false},
+		{"configured owner over a filled slot", filled, "owner", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without a session cookie nobody is signed in
+			r := httptest.NewRequest("GET", "/api/auth/callback", nil)
+			if got := a.mayReplace(r, tt.creds, tt.login); got != tt.want {
+				t.Errorf("mayReplace(%q) = %v, want %v", tt.login, got, tt.want)
+			}
+		})
+	}
+}
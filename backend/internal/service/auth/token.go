@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"twitch-client/internal/credentials"
+
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrTokenInvalid  = errors.New("oauth token is invalid")
+	ValidateEndpoint = "https://id.twitch.tv/oauth2/validate"
+)
+
+const (
+	// How often the token is checked for upcoming expiry
+	tokenCheckInterval = time.Minute
+	// Twitch asks apps to validate their tokens at least once an hour
+	validateInterval = time.Hour
+	// Tokens are renewed this long before they expire
+	refreshMargin = 10 * time.Minute
+)
+
+type validateResponse struct {
+	ClientID  string   `json:"client_id"`
+	Login     string   `json:"login"`
+	Scopes    []string `json:"scopes"`
+	UserID    string   `json:"user_id"`
+	ExpiresIn int      `json:"expires_in"`
+}
+
+// maintainToken keeps the stored token alive for the whole life of the process.
+// It renews the token ahead of expiry and validates it on a schedule, renewed
+// tokens reach the IRC client through the credentials subscribers.
+func (a *authService) maintainToken(role credentials.Role) {
+	creds, err := a.accounts.For(role)
+	if err != nil {
+		log.Printf("Can't maintain %s token: %v", role, err)
+		return
+	}
+
+	var lastValidation time.Time
+
+	ticker := time.NewTicker(tokenCheckInterval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		token, err := creds.Token()
+		if err != nil {
+			continue
+		}
+
+		if token.RefreshToken != "" && !token.Expiry.IsZero() && time.Until(token.Expiry) < refreshMargin {
+			if err := a.refreshToken(role, creds, token); err != nil {
+				log.Printf("Failed to refresh %s oauth token: %v", role, err)
+				continue
+			}
+			lastValidation = time.Time{}
+		}
+
+		if time.Since(lastValidation) < validateInterval {
+			continue
+		}
+
+		err = a.validateToken(creds)
+		switch {
+		case err == nil:
+			lastValidation = time.Now()
+		case errors.Is(err, ErrTokenInvalid) && token.RefreshToken != "":
+			log.Printf("%s OAuth token was rejected, refreshing it", role)
+			if err := a.refreshToken(role, creds, token); err != nil {
+				log.Printf("Failed to refresh %s oauth token: %v", role, err)
+			}
+		default:
+			log.Printf("Failed to validate %s oauth token: %v", role, err)
+		}
+	}
+}
+
+// refreshToken trades the refresh token for a new access token
+func (a *authService) refreshToken(role credentials.Role, creds *credentials.Credentials, token *oauth2.Token) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	// Force the token source to refresh by handing it an expired token
+	expired := &oauth2.Token{
+		RefreshToken: token.RefreshToken,
+		Expiry:       time.Now().Add(-time.Minute),
+	}
+
+	renewed, err := a.oauth2Configs[role].TokenSource(ctx, expired).Token()
+	if err != nil {
+		return err
+	}
+
+	creds.SetToken(a.config.TwitchClientID, renewed)
+	log.Printf("%s OAuth token refreshed, valid until %s", role, renewed.Expiry.Format(time.RFC3339))
+
+	return a.validateToken(creds)
+}
+
+// validateToken checks the current token against /oauth2/validate and stores who it belongs to
+func (a *authService) validateToken(creds *credentials.Credentials) error {
+	_, oauthToken, err := creds.Get()
+	if err != nil {
+		return err
+	}
+
+	response, err := validate(oauthToken)
+	if err != nil {
+		return err
+	}
+
+	var expiry time.Time
+	if response.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+
+	creds.SetValidation(response.Login, response.UserID, expiry)
+
+	return nil
+}
+
+// validate asks Twitch who an access token belongs to
+func validate(oauthToken string) (validateResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ValidateEndpoint, nil)
+	if err != nil {
+		return validateResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "OAuth "+oauthToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return validateResponse{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return validateResponse{}, ErrTokenInvalid
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return validateResponse{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response validateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return validateResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+	"twitch-client/internal/roles"
+	"twitch-client/internal/server/websocket"
+)
+
+// ChatSettings are the chat modes of a channel along with the changes
+// waiting to be reverted
+type ChatSettings struct {
+	helix.ChatSettings
+	PendingReverts []models.ChatSettingsRevert `json:"pending_reverts"`
+}
+
+func (s *Service) GetChatSettings(ctx context.Context, channel string) (ChatSettings, error) {
+	broadcasterID, moderatorID, err := s.chatSettingsIDs(ctx, channel)
+	if err != nil {
+		return ChatSettings{}, err
+	}
+
+	settings, err := s.botHelix.GetChatSettings(ctx, broadcasterID, moderatorID)
+	if err != nil {
+		return ChatSettings{}, fmt.Errorf("failed to get chat settings: %w", err)
+	}
+
+	reverts, err := s.db.GetChatSettingsReverts(channel)
+	if err != nil {
+		return ChatSettings{}, fmt.Errorf("failed to get pending reverts: %w", err)
+	}
+
+	return ChatSettings{ChatSettings: settings, PendingReverts: reverts}, nil
+}
+
+// UpdateChatSettings changes the chat modes of a channel. With revertAfter
+// the changed modes are switched back to how they were once it passes. A
+// change to a mode cancels its pending revert, the latest change wins.
+func (s *Service) UpdateChatSettings(ctx context.Context, channel string, update helix.ChatSettingsUpdate, revertAfter time.Duration, actor roles.Actor) error {
+	if err := authorizeModeration(actor); err != nil {
+		return err
+	}
+
+	broadcasterID, moderatorID, err := s.chatSettingsIDs(ctx, channel)
+	if err != nil {
+		return err
+	}
+
+	var previous helix.ChatSettings
+	if revertAfter > 0 {
+		previous, err = s.botHelix.GetChatSettings(ctx, broadcasterID, moderatorID)
+		if err != nil {
+			return fmt.Errorf("failed to get chat settings: %w", err)
+		}
+	}
+
+	if _, err := s.botHelix.UpdateChatSettings(ctx, broadcasterID, moderatorID, update); err != nil {
+		return fmt.Errorf("failed to update chat settings: %w", err)
+	}
+	log.Printf("%s changed the chat settings of %s", actor.Login, channel)
+
+	for mode, modeUpdate := range update.Modes() {
+		s.cancelRevert(channel, mode)
+
+		if revertAfter <= 0 {
+			continue
+		}
+
+		data, err := json.Marshal(previous.Revert(modeUpdate))
+		if err != nil {
+			return fmt.Errorf("failed to encode revert: %w", err)
+		}
+
+		revert := models.ChatSettingsRevert{
+			Channel:  channel,
+			Mode:     mode,
+			Settings: data,
+			RevertAt: time.Now().Add(revertAfter),
+		}
+		if err := s.db.SaveChatSettingsRevert(revert); err != nil {
+			return fmt.Errorf("failed to save revert: %w", err)
+		}
+		s.scheduleRevert(revert)
+	}
+
+	return nil
+}
+
+// RestoreChatSettingsReverts schedules the reverts stored before the restart,
+// the ones that are overdue run right away. The raids of raid protection
+// reverts were forgotten, they run right away too.
+func (s *Service) RestoreChatSettingsReverts() error {
+	reverts, err := s.db.GetChatSettingsReverts("")
+	if err != nil {
+		return fmt.Errorf("failed to get pending reverts: %w", err)
+	}
+
+	for _, revert := range reverts {
+		if revert.Raid {
+			s.revertNow(revert)
+			continue
+		}
+		s.scheduleRevert(revert)
+	}
+
+	return nil
+}
+
+// revertNow moves a pending revert up to now
+func (s *Service) revertNow(revert models.ChatSettingsRevert) {
+	revert.RevertAt = time.Now()
+	if err := s.db.RescheduleChatSettingsRevert(revert); err != nil {
+		log.Printf("Failed to reschedule revert of %s mode in %s: %v", revert.Mode, revert.Channel, err)
+	}
+	s.scheduleRevert(revert)
+}
+
+func (s *Service) scheduleRevert(revert models.ChatSettingsRevert) {
+	s.revertsMu.Lock()
+	defer s.revertsMu.Unlock()
+
+	s.scheduleRevertLocked(revert)
+}
+
+func (s *Service) scheduleRevertLocked(revert models.ChatSettingsRevert) {
+	key := revert.Channel + "/" + revert.Mode
+
+	if timer, ok := s.reverts[key]; ok {
+		timer.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Until(revert.RevertAt), func() {
+		s.revertsMu.Lock()
+		current := s.reverts[key] == timer
+		s.revertsMu.Unlock()
+
+		// A later change replaced this revert
+		if !current {
+			return
+		}
+
+		err := s.runRevert(revert)
+
+		s.revertsMu.Lock()
+		defer s.revertsMu.Unlock()
+
+		// A change or cancel while the revert ran wins over its outcome
+		if s.reverts[key] != timer {
+			return
+		}
+		delete(s.reverts, key)
+		if err != nil {
+			s.retryRevertLocked(revert, err)
+			return
+		}
+		if err := s.db.DeleteChatSettingsRevert(revert.Channel, revert.Mode); err != nil {
+			log.Printf("Failed to delete revert of %s mode in %s: %v", revert.Mode, revert.Channel, err)
+		}
+	})
+	s.reverts[key] = timer
+}
+
+// retryRevertLocked schedules a failed revert again after a backoff, so a
+// transient Helix error doesn't leave a mode on, and tells the dashboard
+func (s *Service) retryRevertLocked(revert models.ChatSettingsRevert, err error) {
+	revert.Attempts++
+	revert.LastError = err.Error()
+	revert.RevertAt = time.Now().Add(revertBackoff(revert.Attempts))
+	log.Printf("Failed to revert %s mode in %s (attempt %d), retrying at %s: %v", revert.Mode, revert.Channel, revert.Attempts, revert.RevertAt.Format(time.TimeOnly), err)
+
+	if err := s.db.RescheduleChatSettingsRevert(revert); err != nil {
+		log.Printf("Failed to reschedule revert of %s mode in %s: %v", revert.Mode, revert.Channel, err)
+	}
+	s.socket.BroadcastChannelEvent(revert.Channel, websocket.ChatSettingsRevertFailedEvent, revert)
+	s.scheduleRevertLocked(revert)
+}
+
+const (
+	revertRetryMin = 30 * time.Second
+	revertRetryMax = 30 * time.Minute
+)
+
+// revertBackoff doubles the wait with every failed attempt, up to revertRetryMax
+func revertBackoff(attempts int) time.Duration {
+	backoff := revertRetryMin
+	for i := 1; i < attempts && backoff < revertRetryMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, revertRetryMax)
+}
+
+func (s *Service) cancelRevert(channel, mode string) {
+	key := channel + "/" + mode
+
+	s.revertsMu.Lock()
+	if timer, ok := s.reverts[key]; ok {
+		timer.Stop()
+		delete(s.reverts, key)
+	}
+	s.revertsMu.Unlock()
+
+	if err := s.db.DeleteChatSettingsRevert(channel, mode); err != nil {
+		log.Printf("Failed to delete revert of %s mode in %s: %v", mode, channel, err)
+	}
+}
+
+func (s *Service) runRevert(revert models.ChatSettingsRevert) error {
+	ctx, cancel := context.WithTimeout(context.Background(), enforceTimeout)
+	defer cancel()
+
+	var update helix.ChatSettingsUpdate
+	if err := json.Unmarshal(revert.Settings, &update); err != nil {
+		return fmt.Errorf("failed to decode revert: %w", err)
+	}
+
+	broadcasterID, moderatorID, err := s.chatSettingsIDs(ctx, revert.Channel)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.botHelix.UpdateChatSettings(ctx, broadcasterID, moderatorID, update); err != nil {
+		return fmt.Errorf("failed to update chat settings: %w", err)
+	}
+	log.Printf("Reverted %s mode in %s", revert.Mode, revert.Channel)
+
+	return nil
+}
+
+// chatSettingsIDs returns the broadcaster and moderator IDs the chat settings endpoints need
+func (s *Service) chatSettingsIDs(ctx context.Context, channel string) (string, string, error) {
+	query, err := s.moderationQuery(ctx, channel)
+	if err != nil {
+		return "", "", err
+	}
+	return query.Get("broadcaster_id"), query.Get("moderator_id"), nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevertBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{7, 30 * time.Minute},
+		{100, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := revertBackoff(tt.attempts); got != tt.want {
+			t.Errorf("revertBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
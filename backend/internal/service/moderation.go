@@ -0,0 +1,566 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"twitch-client/internal/automod"
+	"twitch-client/internal/db"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+	"twitch-client/internal/roles"
+
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+var (
+	ErrActionNotFound  = errors.New("moderation action not found")
+	ErrCannotUndo      = errors.New("only successful bans and timeouts can be undone")
+	ErrAlreadyUndone   = errors.New("moderation action was already undone")
+	ErrInvalidDuration = errors.New("timeout duration must be between 1 second and 2 weeks")
+	ErrInvalidPermit   = errors.New("permit duration must be between 1 second and 1 hour")
+)
+
+const (
+	// How long enforcing an automod rule may take
+	enforceTimeout = 15 * time.Second
+	// Sent to chat by warn rules without a reason
+	defaultWarning = "uważaj na to, co piszesz"
+	// Sent to chat when link protection warns without a reason
+	defaultLinkWarning = "nie wysyłaj linków bez pozwolenia moderatora"
+	// Twitch caps timeouts at two weeks
+	maxTimeoutSeconds = 1209600
+	maxPermitSeconds  = 3600
+)
+
+// Trigger tells who or what caused a moderation action
+type Trigger struct {
+	// By is the dashboard user or chat moderator, automod rules are named "automod: <rule>"
+	By     string
+	RuleID *int
+	// MessageID and Message are the chat message that caused the action, if any
+	MessageID string
+	Message   string
+}
+
+func automodTrigger(rule models.AutomodRule, message twitch.PrivateMessage) Trigger {
+	ruleID := rule.ID
+	return Trigger{
+		By:        "automod: " + rule.Name,
+		RuleID:    &ruleID,
+		MessageID: message.ID,
+		Message:   message.Message,
+	}
+}
+
+// InterceptMessage runs every chat message through raid detection, link
+// protection and the automod rules
+func (s *Service) InterceptMessage(message twitch.PrivateMessage) {
+	s.raidGuard.TrackMessage(message)
+
+	// Helix calls would hold up the IRC client, actions are enforced in the background
+	if settings, hosts, ok := s.linkGuard.Check(message); ok {
+		log.Printf("Link protection caught %s in %s: %s", message.User.Name, message.Channel, strings.Join(hosts, ", "))
+
+		reason := settings.Reason
+		if reason == "" {
+			reason = "Link to " + hosts[0]
+		}
+		warning := settings.Reason
+		if warning == "" {
+			warning = defaultLinkWarning
+		}
+
+		trigger := Trigger{By: "link protection", MessageID: message.ID, Message: message.Message}
+		go s.enforce(message, settings.Action, settings.DurationSeconds, reason, warning, trigger)
+		return
+	}
+
+	// Rules on the account age look it up over Helix
+	go s.checkAutomod(message)
+}
+
+// checkAutomod enforces the first automod rule the message breaks
+func (s *Service) checkAutomod(message twitch.PrivateMessage) {
+	rule, ok := s.automod.Check(message)
+	if !ok {
+		return
+	}
+
+	log.Printf("Automod rule %q matched %s in %s: %s", rule.Name, message.User.Name, message.Channel, rule.Action)
+
+	reason := rule.Reason
+	if reason == "" {
+		reason = rule.Name
+	}
+	s.enforce(message, rule.Action, rule.DurationSeconds, reason, rule.Reason, automodTrigger(rule, message))
+}
+
+// enforce takes an automod action against the author of a message, warning is
+// what warn actions send to chat
+func (s *Service) enforce(message twitch.PrivateMessage, action string, duration int, reason, warning string, trigger Trigger) {
+	ctx, cancel := context.WithTimeout(context.Background(), enforceTimeout)
+	defer cancel()
+
+	var err error
+	switch action {
+	case automod.ActionDelete:
+		err = s.deleteMessage(ctx, message.Channel, message.User.ID, message.User.Name, reason, trigger)
+	case automod.ActionTimeout:
+		err = s.restrictUser(ctx, message.Channel, message.User.ID, message.User.Name, duration, reason, trigger)
+	case automod.ActionBan:
+		err = s.restrictUser(ctx, message.Channel, message.User.ID, message.User.Name, 0, reason, trigger)
+	case automod.ActionWarn:
+		err = s.warnUser(message.Channel, message.User.ID, message.User.Name, warning, trigger)
+	}
+
+	if err != nil {
+		log.Printf("Failed to %s %s: %v", action, message.User.Name, err)
+	}
+}
+
+// authorizeModeration is the permission check shared by the dashboard
+// endpoints and the chat commands
+func authorizeModeration(actor roles.Actor) error {
+	return actor.Require(roles.Moderator)
+}
+
+func (s *Service) BanUser(ctx context.Context, channel, username, reason string, actor roles.Actor) error {
+	if err := authorizeModeration(actor); err != nil {
+		return err
+	}
+
+	userInfo, err := s.GetUserInfo(ctx, username)
+	if err != nil {
+		return fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	return s.restrictUser(ctx, channel, userInfo.ID, userInfo.Login, 0, reason, Trigger{By: actor.Login})
+}
+
+// TimeoutUser keeps a user out of chat for duration seconds
+func (s *Service) TimeoutUser(ctx context.Context, channel, username string, duration int, reason string, actor roles.Actor) error {
+	if err := authorizeModeration(actor); err != nil {
+		return err
+	}
+
+	if duration < 1 || duration > maxTimeoutSeconds {
+		return ErrInvalidDuration
+	}
+
+	userInfo, err := s.GetUserInfo(ctx, username)
+	if err != nil {
+		return fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	return s.restrictUser(ctx, channel, userInfo.ID, userInfo.Login, duration, reason, Trigger{By: actor.Login})
+}
+
+// UnbanUser lifts a ban or timeout
+func (s *Service) UnbanUser(ctx context.Context, channel, username, reason string, actor roles.Actor) error {
+	if err := authorizeModeration(actor); err != nil {
+		return err
+	}
+
+	userInfo, err := s.GetUserInfo(ctx, username)
+	if err != nil {
+		return fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	return s.unbanUser(ctx, channel, userInfo.ID, userInfo.Login, reason, Trigger{By: actor.Login})
+}
+
+// DeleteMessage removes a single chat message, the author and text are taken
+// from the chat history when the message was stored already
+func (s *Service) DeleteMessage(ctx context.Context, channel, messageID string, actor roles.Actor) error {
+	if err := authorizeModeration(actor); err != nil {
+		return err
+	}
+
+	trigger := Trigger{By: actor.Login, MessageID: messageID}
+
+	message, err := s.db.GetChatMessage(messageID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Failed to look up message %s: %v", messageID, err)
+	}
+	trigger.Message = message.Message
+
+	return s.deleteMessage(ctx, channel, message.UserID, message.Username, "", trigger)
+}
+
+// ClearChat removes every message in a channel's chat
+func (s *Service) ClearChat(ctx context.Context, channel string, actor roles.Actor) error {
+	if err := authorizeModeration(actor); err != nil {
+		return err
+	}
+
+	entry := &models.ModerationAction{
+		Channel: channel,
+		Action:  models.ActionClear,
+	}
+	trigger := Trigger{By: actor.Login}
+
+	query, err := s.moderationQuery(ctx, channel)
+	if err != nil {
+		s.audit(entry, trigger, 0, nil, err)
+		return err
+	}
+
+	err = s.botHelix.Delete(ctx, "/moderation/chat", query)
+	s.audit(entry, trigger, http.StatusNoContent, nil, err)
+	if err != nil {
+		return fmt.Errorf("failed to clear chat: %w", err)
+	}
+
+	return nil
+}
+
+// restrictUser bans a user, or times them out when duration is greater than zero
+func (s *Service) restrictUser(ctx context.Context, channel, userID, username string, duration int, reason string, trigger Trigger) error {
+	action := models.ActionBan
+	if duration > 0 {
+		action = models.ActionTimeout
+	}
+
+	entry := &models.ModerationAction{
+		Channel:         channel,
+		UserID:          userID,
+		Username:        username,
+		Action:          action,
+		Reason:          reason,
+		DurationSeconds: duration,
+	}
+
+	query, err := s.moderationQuery(ctx, channel)
+	if err != nil {
+		s.audit(entry, trigger, 0, nil, err)
+		return err
+	}
+
+	body := struct {
+		Data struct {
+			UserID   string `json:"user_id"`
+			Duration int    `json:"duration,omitempty"`
+			Reason   string `json:"reason"`
+		} `json:"data"`
+	}{}
+	body.Data.UserID = userID
+	body.Data.Duration = duration
+	body.Data.Reason = reason
+
+	var response json.RawMessage
+	err = s.botHelix.Post(ctx, "/moderation/bans", query, body, &response)
+	s.audit(entry, trigger, http.StatusOK, response, err)
+	if err != nil {
+		return fmt.Errorf("failed to %s user: %w", action, err)
+	}
+
+	return nil
+}
+
+// unbanUser lifts a ban or timeout
+func (s *Service) unbanUser(ctx context.Context, channel, userID, username, reason string, trigger Trigger) error {
+	entry := &models.ModerationAction{
+		Channel:  channel,
+		UserID:   userID,
+		Username: username,
+		Action:   models.ActionUnban,
+		Reason:   reason,
+	}
+
+	query, err := s.moderationQuery(ctx, channel)
+	if err != nil {
+		s.audit(entry, trigger, 0, nil, err)
+		return err
+	}
+	query.Set("user_id", userID)
+
+	err = s.botHelix.Delete(ctx, "/moderation/bans", query)
+	s.audit(entry, trigger, http.StatusNoContent, nil, err)
+	if err != nil {
+		return fmt.Errorf("failed to unban user: %w", err)
+	}
+
+	return nil
+}
+
+// deleteMessage removes the chat message of the trigger, without a message ID
+// Helix would clear the whole chat
+func (s *Service) deleteMessage(ctx context.Context, channel, userID, username, reason string, trigger Trigger) error {
+	entry := &models.ModerationAction{
+		Channel:  channel,
+		UserID:   userID,
+		Username: username,
+		Action:   models.ActionDelete,
+		Reason:   reason,
+	}
+
+	query, err := s.moderationQuery(ctx, channel)
+	if err != nil {
+		s.audit(entry, trigger, 0, nil, err)
+		return err
+	}
+	if trigger.MessageID == "" {
+		err := errors.New("message ID is required")
+		s.audit(entry, trigger, 0, nil, err)
+		return err
+	}
+	query.Set("message_id", trigger.MessageID)
+
+	err = s.botHelix.Delete(ctx, "/moderation/chat", query)
+	s.audit(entry, trigger, http.StatusNoContent, nil, err)
+	if err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	return nil
+}
+
+// warnUser replies to a user in chat
+func (s *Service) warnUser(channel, userID, username, warning string, trigger Trigger) error {
+	if warning == "" {
+		warning = defaultWarning
+	}
+
+	err := s.SendMessage(channel, fmt.Sprintf("@%s, %s", username, warning))
+	s.audit(&models.ModerationAction{
+		Channel:  channel,
+		UserID:   userID,
+		Username: username,
+		Action:   models.ActionWarn,
+		Reason:   warning,
+	}, trigger, 0, nil, err)
+
+	return err
+}
+
+// UndoModerationAction lifts a ban or timeout from the audit log through Helix
+func (s *Service) UndoModerationAction(ctx context.Context, id int, actor roles.Actor) (models.ModerationAction, error) {
+	if err := authorizeModeration(actor); err != nil {
+		return models.ModerationAction{}, err
+	}
+
+	action, err := s.db.GetModerationAction(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ModerationAction{}, ErrActionNotFound
+	}
+	if err != nil {
+		return models.ModerationAction{}, fmt.Errorf("failed to get moderation action: %w", err)
+	}
+
+	if !action.Succeeded || (action.Action != models.ActionBan && action.Action != models.ActionTimeout) {
+		return models.ModerationAction{}, ErrCannotUndo
+	}
+	if action.UndoneAt != nil {
+		return models.ModerationAction{}, ErrAlreadyUndone
+	}
+
+	reason := fmt.Sprintf("Undo of moderation action #%d", action.ID)
+	if err := s.unbanUser(ctx, action.Channel, action.UserID, action.Username, reason, Trigger{By: actor.Login}); err != nil {
+		return models.ModerationAction{}, err
+	}
+
+	err = s.db.MarkModerationActionUndone(action.ID, actor.Login)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ModerationAction{}, ErrAlreadyUndone
+	}
+	if err != nil {
+		return models.ModerationAction{}, fmt.Errorf("failed to mark moderation action undone: %w", err)
+	}
+
+	return s.db.GetModerationAction(action.ID)
+}
+
+func (s *Service) GetModerationLog(filter db.ModerationLogFilter) ([]models.ModerationAction, error) {
+	return s.db.GetModerationLog(filter)
+}
+
+// moderationQuery returns the broadcaster and moderator IDs moderation endpoints need
+func (s *Service) moderationQuery(ctx context.Context, channel string) (url.Values, error) {
+	broadcasterID, err := s.GetBroadcasterID(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcaster ID: %w", err)
+	}
+
+	moderatorID, err := s.botUserID()
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("broadcaster_id", broadcasterID)
+	query.Set("moderator_id", moderatorID)
+
+	return query, nil
+}
+
+// audit stores a moderation action in the audit log along with what Helix
+// answered, successStatus is the status Twitch documents for the endpoint.
+// A failure to store the entry doesn't undo the action.
+func (s *Service) audit(entry *models.ModerationAction, trigger Trigger, successStatus int, response []byte, err error) {
+	entry.Username = strings.ToLower(entry.Username)
+	entry.Moderator = s.botLogin()
+	entry.TriggeredBy = trigger.By
+	entry.RuleID = trigger.RuleID
+	entry.MessageID = trigger.MessageID
+	entry.Message = trigger.Message
+	entry.Succeeded = err == nil
+
+	var apiErr *helix.APIError
+	switch {
+	case err == nil:
+		entry.HelixStatus = successStatus
+		entry.HelixResponse = string(response)
+	case errors.As(err, &apiErr):
+		entry.HelixStatus = apiErr.StatusCode
+		entry.HelixResponse = apiErr.Error()
+	default:
+		entry.HelixResponse = err.Error()
+	}
+
+	if err := s.db.CreateModerationAction(entry); err != nil {
+		log.Printf("Failed to record %s of %s: %v", entry.Action, entry.Username, err)
+	}
+}
+
+func (s *Service) botLogin() string {
+	login, _ := s.accounts.Bot.Identity()
+	return login
+}
+
+func (s *Service) GetAutomodRules() ([]models.AutomodRule, error) {
+	return s.db.GetAutomodRules()
+}
+
+func (s *Service) GetAutomodRule(id int) (models.AutomodRule, error) {
+	return s.db.GetAutomodRule(id)
+}
+
+func (s *Service) AddAutomodRule(rule *models.AutomodRule) error {
+	if err := automod.Validate(*rule); err != nil {
+		return err
+	}
+
+	if err := s.db.CreateAutomodRule(rule); err != nil {
+		return fmt.Errorf("failed to create automod rule: %w", err)
+	}
+
+	return s.automod.Reload()
+}
+
+func (s *Service) UpdateAutomodRule(rule *models.AutomodRule) error {
+	if err := automod.Validate(*rule); err != nil {
+		return err
+	}
+
+	if err := s.db.UpdateAutomodRule(rule); err != nil {
+		return fmt.Errorf("failed to update automod rule: %w", err)
+	}
+
+	return s.automod.Reload()
+}
+
+func (s *Service) DeleteAutomodRule(id int) error {
+	if err := s.db.DeleteAutomodRule(id); err != nil {
+		return fmt.Errorf("failed to delete automod rule: %w", err)
+	}
+
+	return s.automod.Reload()
+}
+
+func (s *Service) GetPhraseLists() ([]models.PhraseList, error) {
+	return s.db.GetPhraseLists()
+}
+
+// SavePhraseList creates or updates a phrase list and replaces its phrases
+func (s *Service) SavePhraseList(list *models.PhraseList) error {
+	if err := automod.ValidatePhraseList(*list); err != nil {
+		return err
+	}
+
+	if err := s.db.SavePhraseList(list); err != nil {
+		return fmt.Errorf("failed to save phrase list: %w", err)
+	}
+
+	return s.automod.Reload()
+}
+
+func (s *Service) DeletePhraseList(id int) error {
+	if err := s.db.DeletePhraseList(id); err != nil {
+		return fmt.Errorf("failed to delete phrase list: %w", err)
+	}
+
+	return s.automod.Reload()
+}
+
+// GetLinkProtection returns the link protection settings and allowlist of a channel
+func (s *Service) GetLinkProtection(channel string) (models.LinkProtection, error) {
+	return s.db.GetLinkProtection(channel)
+}
+
+func (s *Service) SaveLinkProtection(settings *models.LinkProtection) error {
+	if err := automod.ValidateLinkProtection(*settings); err != nil {
+		return err
+	}
+
+	if err := s.db.SaveLinkProtection(settings); err != nil {
+		return fmt.Errorf("failed to save link protection: %w", err)
+	}
+
+	return s.linkGuard.Reload()
+}
+
+// AllowDomain lets everyone post links to a domain and its subdomains in a channel
+func (s *Service) AllowDomain(channel, domain string) error {
+	domain, err := automod.NormalizeDomain(domain)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.AddAllowedDomain(channel, domain); err != nil {
+		return fmt.Errorf("failed to allow domain: %w", err)
+	}
+
+	return s.linkGuard.Reload()
+}
+
+func (s *Service) DisallowDomain(channel, domain string) error {
+	domain, err := automod.NormalizeDomain(domain)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.RemoveAllowedDomain(channel, domain); err != nil {
+		return fmt.Errorf("failed to disallow domain: %w", err)
+	}
+
+	return s.linkGuard.Reload()
+}
+
+// PermitUser lets a user post links in a channel for duration seconds
+func (s *Service) PermitUser(channel, username string, duration int, actor roles.Actor) error {
+	if err := authorizeModeration(actor); err != nil {
+		return err
+	}
+
+	if duration < 1 || duration > maxPermitSeconds {
+		return ErrInvalidPermit
+	}
+
+	s.linkGuard.Permit(channel, username, time.Duration(duration)*time.Second)
+	log.Printf("%s permitted %s to post links in %s for %ds", actor.Login, username, channel, duration)
+
+	return nil
+}
+
+// TestAutomodRule tries a rule on a sample message without storing it
+func (s *Service) TestAutomodRule(rule models.AutomodRule, sample automod.Sample) (automod.Result, error) {
+	return s.automod.Test(rule, sample)
+}
@@ -0,0 +1,189 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/server/websocket"
+)
+
+const (
+	chatMinOptions = 2
+	chatMaxOptions = 10
+)
+
+// chatPoll runs polls locally, viewers vote with !vote <n> in chat
+type chatPoll struct {
+	Duration   int
+	Question   string
+	Options    []string
+	Votes      map[string]int // username -> option number (1-based)
+	State      int
+	StartedAt  time.Time
+	generation int
+	timer      *time.Timer
+	channel    string
+	db         Store
+	socket     *websocket.WebSocket
+	mu         sync.Mutex
+}
+
+func newChatPoll(db Store, socket *websocket.WebSocket, channel string) *chatPoll {
+	return &chatPoll{
+		State:   StateIdle,
+		Votes:   make(map[string]int),
+		channel: channel,
+		db:      db,
+		socket:  socket,
+	}
+}
+
+// StartPoll opens a new poll. When duration is greater than zero the poll
+// closes itself after that many seconds.
+func (p *chatPoll) StartPoll(ctx context.Context, question string, options []string, duration int) error {
+	question, options, err := cleanPoll(question, options, chatMinOptions, chatMaxOptions)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.State == StateActive {
+		return ErrPollInProgress
+	}
+
+	p.State = StateActive
+	p.Question = question
+	p.Options = options
+	p.Duration = duration
+	p.Votes = make(map[string]int)
+	p.StartedAt = time.Now()
+	p.generation++
+
+	if duration > 0 {
+		generation := p.generation
+		p.timer = time.AfterFunc(time.Duration(duration)*time.Second, func() {
+			if _, err := p.endPoll(generation); err != nil && !errors.Is(err, ErrPollNotStarted) {
+				log.Printf("Failed to close poll: %v", err)
+			}
+		})
+	}
+
+	p.socket.BroadcastChannelEvent(p.channel, websocket.PollStartEvent, p.resultsLocked())
+
+	return nil
+}
+
+func (p *chatPoll) HandleVote(username string, vote int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.State == StateIdle {
+		return ErrPollNotStarted
+	}
+
+	if vote < 1 || vote > len(p.Options) {
+		return ErrInvalidOption
+	}
+
+	// Users can change their vote, only the latest one counts
+	p.Votes[strings.ToLower(username)] = vote
+
+	p.socket.BroadcastChannelEvent(p.channel, websocket.PollUpdateEvent, p.resultsLocked())
+
+	return nil
+}
+
+func (p *chatPoll) Results() Results {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.resultsLocked()
+}
+
+func (p *chatPoll) EndPoll(ctx context.Context) (Results, error) {
+	p.mu.Lock()
+	generation := p.generation
+	p.mu.Unlock()
+
+	return p.endPoll(generation)
+}
+
+func (p *chatPoll) Active() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.State == StateActive
+}
+
+// endPoll closes the poll only if it is still the one identified by generation,
+// so a stale timer can't close a poll that was started after it.
+func (p *chatPoll) endPoll(generation int) (Results, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.State == StateIdle || p.generation != generation {
+		return Results{}, ErrPollNotStarted
+	}
+
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+
+	results := p.resultsLocked()
+	results.Active = false
+
+	p.State = StateIdle
+	p.Question = ""
+	p.Options = nil
+	p.Votes = make(map[string]int)
+
+	p.socket.BroadcastChannelEvent(p.channel, websocket.PollEndEvent, results)
+
+	return results, saveResults(p.db, p.channel, results)
+}
+
+func (p *chatPoll) resultsLocked() Results {
+	options := make([]models.PollOption, len(p.Options))
+	for i, title := range p.Options {
+		options[i] = models.PollOption{Title: title}
+	}
+
+	for _, vote := range p.Votes {
+		options[vote-1].Votes++
+	}
+
+	results := Results{
+		Active:     p.State == StateActive,
+		Mode:       ModeChat,
+		Question:   p.Question,
+		Options:    options,
+		TotalVotes: len(p.Votes),
+		Duration:   p.Duration,
+		StartedAt:  p.StartedAt,
+	}
+
+	if p.Duration > 0 {
+		results.EndsAt = p.StartedAt.Add(time.Duration(p.Duration) * time.Second)
+	}
+
+	return results
+}
+
+func (p *chatPoll) StartPrediction(ctx context.Context, title string, outcomes []string, window int) error {
+	return ErrNotSupported
+}
+
+func (p *chatPoll) EndPrediction(ctx context.Context, winner int) (Prediction, error) {
+	return Prediction{}, ErrNotSupported
+}
+
+func (p *chatPoll) Prediction(ctx context.Context) (Prediction, error) {
+	return Prediction{}, ErrNotSupported
+}
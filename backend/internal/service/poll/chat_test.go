@@ -0,0 +1,117 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"twitch-client/internal/server/websocket"
+	"twitch-client/internal/server/websocket/ratelimiter"
+)
+
+// newTestDeps returns a running websocket hub and an in-memory poll history
+func newTestDeps(t *testing.T) (*websocket.WebSocket, *fakeStore) {
+	t.Helper()
+
+	rl := ratelimiter.NewRateLimiter(time.Second)
+	socket := websocket.NewWebSocket(&rl)
+	go socket.Run()
+
+	return socket, &fakeStore{}
+}
+
+func newTestChatPoll(t *testing.T) (*chatPoll, *fakeStore) {
+	socket, store := newTestDeps(t)
+	return newChatPoll(store, socket, "streamer"), store
+}
+
+func TestChatPollVotes(t *testing.T) {
+	p, store := newTestChatPoll(t)
+	ctx := context.Background()
+
+	if err := p.HandleVote("viewer", 1); !errors.Is(err, ErrPollNotStarted) {
+		t.Fatalf("expected ErrPollNotStarted before the poll, got %v", err)
+	}
+
+	if err := p.StartPoll(ctx, "Best map?", []string{"Dust", "Mirage", "Inferno"}, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	votes := []struct {
+		user string
+		vote int
+		want error
+	}{
+		{"alice", 1, nil},
+		{"bob", 2, nil},
+		{"carol", 4, ErrInvalidOption},
+		{"dave", 0, ErrInvalidOption},
+		// Votes are counted once per user, case-insensitively, the latest one wins
+		{"Alice", 2, nil},
+	}
+	for _, v := range votes {
+		if err := p.HandleVote(v.user, v.vote); !errors.Is(err, v.want) {
+			t.Errorf("vote %d by %s: expected %v, got %v", v.vote, v.user, v.want, err)
+		}
+	}
+
+	results, err := p.EndPoll(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results.Active || results.TotalVotes != 2 || results.Options[0].Votes != 0 || results.Options[1].Votes != 2 {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if store.inserts() != 1 {
+		t.Errorf("expected the poll to be stored once, got %d", store.inserts())
+	}
+
+	if _, err := p.EndPoll(ctx); !errors.Is(err, ErrPollNotStarted) {
+		t.Errorf("expected ErrPollNotStarted after the poll, got %v", err)
+	}
+}
+
+func TestChatPollStaleTimer(t *testing.T) {
+	p, _ := newTestChatPoll(t)
+	ctx := context.Background()
+
+	if err := p.StartPoll(ctx, "First?", []string{"Yes", "No"}, 60); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := p.generation
+
+	if _, err := p.EndPoll(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.StartPoll(ctx, "Second?", []string{"Yes", "No"}, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// What the timer of the first poll would do if it fired now
+	if _, err := p.endPoll(first); !errors.Is(err, ErrPollNotStarted) {
+		t.Errorf("expected the stale timer to be ignored, got %v", err)
+	}
+	if results := p.Results(); !results.Active || results.Question != "Second?" {
+		t.Errorf("expected the second poll to keep running, got %+v", results)
+	}
+}
+
+func TestChatPollTimedClose(t *testing.T) {
+	p, store := newTestChatPoll(t)
+
+	if err := p.StartPoll(context.Background(), "Quick?", []string{"Yes", "No"}, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for p.Active() && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if p.Active() {
+		t.Fatal("expected the poll to close after its duration")
+	}
+	if store.inserts() != 1 {
+		t.Errorf("expected the poll to be stored once, got %d", store.inserts())
+	}
+}
@@ -0,0 +1,46 @@
+package poll
+
+import (
+	"sync"
+	"twitch-client/internal/helix"
+	"twitch-client/internal/server/websocket"
+)
+
+// Manager keeps a separate poll service for every channel
+type Manager struct {
+	services map[string]Service
+	db       Store
+	socket   *websocket.WebSocket
+	helix    *helix.Client
+	mu       sync.Mutex
+}
+
+func NewManager(db Store, socket *websocket.WebSocket, hc *helix.Client) *Manager {
+	return &Manager{
+		services: make(map[string]Service),
+		db:       db,
+		socket:   socket,
+		helix:    hc,
+	}
+}
+
+// For returns the poll service of a channel, creating it on first use
+func (m *Manager) For(channel string) Service {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	service, ok := m.services[channel]
+	if !ok {
+		service = NewService(m.db, m.socket, m.helix, channel)
+		m.services[channel] = service
+	}
+	return service
+}
+
+// Remove drops the poll service of a channel the bot left
+func (m *Manager) Remove(channel string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.services, channel)
+}
@@ -1,65 +1,237 @@
 package poll
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"sync"
+	"time"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+	"twitch-client/internal/server/websocket"
 )
 
 var (
-	ErrPollNotStarted = errors.New("poll not started")
-	Endpoint          = "https://api.twitch.tv/helix/polls"
+	ErrPollNotStarted       = errors.New("poll not started")
+	ErrPollInProgress       = errors.New("poll already in progress")
+	ErrInvalidOption        = errors.New("invalid poll option")
+	ErrInvalidQuestion      = errors.New("poll question is required")
+	ErrNotEnoughOptions     = errors.New("poll has too few or too many options")
+	ErrInvalidDuration      = errors.New("invalid poll duration")
+	ErrInvalidMode          = errors.New("invalid poll mode")
+	ErrNotSupported         = errors.New("not supported in the current poll mode")
+	ErrPredictionNotStarted = errors.New("prediction not started")
+	Endpoint                = "/polls"
+	PredictionsEndpoint     = "/predictions"
 )
 
-type pollservice struct {
-	ChannelPointsVoting  bool
-	ChannelPointsPerVote int
-	Duration             int
-	Question             string
-	Votes                map[string]int
-	State                int
-	mu                   sync.Mutex
+const (
+	StateIdle = iota
+	StateActive
+)
+
+// Mode selects where polls are run
+type Mode string
+
+const (
+	// ModeChat runs polls locally, viewers vote with !vote in chat
+	ModeChat Mode = "chat"
+	// ModeTwitch mirrors native Twitch polls and predictions through Helix
+	ModeTwitch Mode = "twitch"
+)
+
+// Results is a snapshot of the current poll with per-option tallies
+type Results struct {
+	Active     bool                `json:"active"`
+	Mode       Mode                `json:"mode"`
+	Question   string              `json:"question"`
+	Options    []models.PollOption `json:"options"`
+	TotalVotes int                 `json:"total_votes"`
+	Duration   int                 `json:"duration_seconds"`
+	StartedAt  time.Time           `json:"started_at"`
+	EndsAt     time.Time           `json:"ends_at"`
+}
+
+type PredictionOutcome struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	Users         int    `json:"users"`
+	ChannelPoints int    `json:"channel_points"`
+}
+
+type Prediction struct {
+	ID               string              `json:"id"`
+	Title            string              `json:"title"`
+	Status           string              `json:"status"`
+	WinningOutcomeID string              `json:"winning_outcome_id"`
+	Outcomes         []PredictionOutcome `json:"outcomes"`
+	WindowSeconds    int                 `json:"prediction_window"`
+	CreatedAt        time.Time           `json:"created_at"`
 }
 
+// Service runs the polls of a channel. The context bounds the Twitch API
+// calls made in twitch mode.
 type Service interface {
 	HandleVote(username string, vote int) error
-	Results() map[string]int
-	StartPoll(question string)
-	EndPoll()
+	Results() Results
+	StartPoll(ctx context.Context, question string, options []string, duration int) error
+	EndPoll(ctx context.Context) (Results, error)
+	History(limit int) ([]models.Poll, error)
+
+	Mode() Mode
+	SetMode(mode Mode) error
+	SetChannelPoints(enabled bool, perVote int)
+
+	StartPrediction(ctx context.Context, title string, outcomes []string, window int) error
+	// EndPrediction resolves the prediction with the given outcome number (1-based),
+	// a winner of 0 cancels it and refunds the channel points
+	EndPrediction(ctx context.Context, winner int) (Prediction, error)
+	Prediction(ctx context.Context) (Prediction, error)
+}
+
+// backend is implemented by every place a poll can run in
+type backend interface {
+	HandleVote(username string, vote int) error
+	Results() Results
+	StartPoll(ctx context.Context, question string, options []string, duration int) error
+	EndPoll(ctx context.Context) (Results, error)
+	Active() bool
+
+	StartPrediction(ctx context.Context, title string, outcomes []string, window int) error
+	EndPrediction(ctx context.Context, winner int) (Prediction, error)
+	Prediction(ctx context.Context) (Prediction, error)
+}
+
+// Store keeps the history of finished polls, *db.Database implements it
+type Store interface {
+	CreatePoll(poll *models.Poll) error
+	GetPolls(channel string, limit int) ([]models.Poll, error)
+}
+
+type pollservice struct {
+	mode    Mode
+	chat    *chatPoll
+	twitch  *twitchPoll
+	channel string
+	db      Store
+	mu      sync.RWMutex
 }
 
-func NewService() Service {
+// NewService creates a poll service of a channel running in chat mode, in
+// twitch mode the native Twitch polls of the same channel are used.
+func NewService(db Store, socket *websocket.WebSocket, hc *helix.Client, channel string) Service {
 	return &pollservice{
-		State: 0,
-		Votes: make(map[string]int),
+		mode:    ModeChat,
+		chat:    newChatPoll(db, socket, channel),
+		twitch:  newTwitchPoll(db, socket, hc, channel),
+		channel: channel,
+		db:      db,
 	}
 }
 
-func (p *pollservice) StartPoll(question string) {
-	p.State = 1
-	p.Question = question
+func (p *pollservice) backend() backend {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.mode == ModeTwitch {
+		return p.twitch
+	}
+	return p.chat
 }
 
 func (p *pollservice) HandleVote(username string, vote int) error {
+	return p.backend().HandleVote(username, vote)
+}
+
+func (p *pollservice) Results() Results {
+	return p.backend().Results()
+}
+
+func (p *pollservice) StartPoll(ctx context.Context, question string, options []string, duration int) error {
+	return p.backend().StartPoll(ctx, question, options, duration)
+}
+
+func (p *pollservice) EndPoll(ctx context.Context) (Results, error) {
+	return p.backend().EndPoll(ctx)
+}
+
+func (p *pollservice) History(limit int) ([]models.Poll, error) {
+	return p.db.GetPolls(p.channel, limit)
+}
+
+func (p *pollservice) Mode() Mode {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.mode
+}
+
+// SetMode switches between chat and native Twitch polls. The mode can't be
+// changed while a poll is running.
+func (p *pollservice) SetMode(mode Mode) error {
+	if mode != ModeChat && mode != ModeTwitch {
+		return ErrInvalidMode
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if p.State == 0 {
-		return ErrPollNotStarted
+	if p.chat.Active() || p.twitch.Active() {
+		return ErrPollInProgress
 	}
 
-	p.Votes[username] = vote
-
+	p.mode = mode
 	return nil
 }
 
-func (p *pollservice) Results() map[string]int {
-	return p.Votes
+func (p *pollservice) SetChannelPoints(enabled bool, perVote int) {
+	p.twitch.SetChannelPoints(enabled, perVote)
 }
 
-func (p *pollservice) EndPoll() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+func (p *pollservice) StartPrediction(ctx context.Context, title string, outcomes []string, window int) error {
+	return p.backend().StartPrediction(ctx, title, outcomes, window)
+}
+
+func (p *pollservice) EndPrediction(ctx context.Context, winner int) (Prediction, error) {
+	return p.backend().EndPrediction(ctx, winner)
+}
+
+func (p *pollservice) Prediction(ctx context.Context) (Prediction, error) {
+	return p.backend().Prediction(ctx)
+}
+
+// cleanPoll trims the question and drops empty options
+func cleanPoll(question string, options []string, min, max int) (string, []string, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return "", nil, ErrInvalidQuestion
+	}
+
+	cleaned := make([]string, 0, len(options))
+	for _, option := range options {
+		if option = strings.TrimSpace(option); option != "" {
+			cleaned = append(cleaned, option)
+		}
+	}
+	if len(cleaned) < min || len(cleaned) > max {
+		return "", nil, ErrNotEnoughOptions
+	}
+
+	return question, cleaned, nil
+}
+
+// saveResults stores a finished poll in the history
+func saveResults(db Store, channel string, results Results) error {
+	record := &models.Poll{
+		Channel:         channel,
+		Mode:            string(results.Mode),
+		Question:        results.Question,
+		Options:         results.Options,
+		TotalVotes:      results.TotalVotes,
+		DurationSeconds: results.Duration,
+		StartedAt:       results.StartedAt,
+		EndedAt:         time.Now(),
+	}
 
-	p.State = 0
-	p.Votes = make(map[string]int)
+	return db.CreatePoll(record)
 }
@@ -0,0 +1,538 @@
+package poll
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+	"twitch-client/internal/server/websocket"
+)
+
+const (
+	twitchMinChoices  = 2
+	twitchMaxChoices  = 5
+	twitchMinDuration = 15
+	twitchMaxDuration = 1800
+
+	predictionMinOutcomes = 2
+	predictionMaxOutcomes = 10
+	predictionMinWindow   = 30
+	predictionMaxWindow   = 1800
+
+	// How often an active Twitch poll is fetched to push live results
+	refreshInterval = 5 * time.Second
+)
+
+type helixPoll struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Choices []struct {
+		ID                 string `json:"id"`
+		Title              string `json:"title"`
+		Votes              int    `json:"votes"`
+		ChannelPointsVotes int    `json:"channel_points_votes"`
+	} `json:"choices"`
+	Status    string    `json:"status"`
+	Duration  int       `json:"duration"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+type helixPrediction struct {
+	ID               string `json:"id"`
+	Title            string `json:"title"`
+	WinningOutcomeID string `json:"winning_outcome_id"`
+	Outcomes         []struct {
+		ID            string `json:"id"`
+		Title         string `json:"title"`
+		Users         int    `json:"users"`
+		ChannelPoints int    `json:"channel_points"`
+	} `json:"outcomes"`
+	PredictionWindow int       `json:"prediction_window"`
+	Status           string    `json:"status"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// twitchPoll mirrors native Twitch polls and predictions of a channel
+type twitchPoll struct {
+	ChannelPointsVoting  bool
+	ChannelPointsPerVote int
+	current              *helixPoll
+	prediction           *helixPrediction
+	// set while a poll or prediction is being created on Twitch, the lock isn't held meanwhile
+	creatingPoll       bool
+	creatingPrediction bool
+	stop               chan struct{}
+	db                 Store
+	socket             *websocket.WebSocket
+	helix              *helix.Client
+	channel            string
+	mu                 sync.Mutex
+}
+
+func newTwitchPoll(db Store, socket *websocket.WebSocket, hc *helix.Client, channel string) *twitchPoll {
+	return &twitchPoll{
+		ChannelPointsPerVote: 1,
+		db:                   db,
+		socket:               socket,
+		helix:                hc,
+		channel:              channel,
+	}
+}
+
+func (p *twitchPoll) SetChannelPoints(enabled bool, perVote int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if perVote < 1 {
+		perVote = 1
+	}
+
+	p.ChannelPointsVoting = enabled
+	p.ChannelPointsPerVote = perVote
+}
+
+// StartPoll creates a native poll. The Helix calls run without holding the
+// lock so chat and the dashboard aren't blocked by a slow Twitch API.
+func (p *twitchPoll) StartPoll(ctx context.Context, question string, options []string, duration int) error {
+	question, options, err := cleanPoll(question, options, twitchMinChoices, twitchMaxChoices)
+	if err != nil {
+		return err
+	}
+
+	if duration < twitchMinDuration || duration > twitchMaxDuration {
+		return ErrInvalidDuration
+	}
+
+	broadcasterID, err := p.broadcasterID(ctx)
+	if err != nil {
+		return err
+	}
+
+	type choice struct {
+		Title string `json:"title"`
+	}
+
+	body := struct {
+		BroadcasterID              string   `json:"broadcaster_id"`
+		Title                      string   `json:"title"`
+		Choices                    []choice `json:"choices"`
+		Duration                   int      `json:"duration"`
+		ChannelPointsVotingEnabled bool     `json:"channel_points_voting_enabled"`
+		ChannelPointsPerVote       int      `json:"channel_points_per_vote,omitempty"`
+	}{
+		BroadcasterID: broadcasterID,
+		Title:         question,
+		Duration:      duration,
+	}
+
+	for _, option := range options {
+		body.Choices = append(body.Choices, choice{Title: option})
+	}
+
+	p.mu.Lock()
+	if p.creatingPoll || p.current != nil && p.current.Status == "ACTIVE" {
+		p.mu.Unlock()
+		return ErrPollInProgress
+	}
+	p.creatingPoll = true
+	body.ChannelPointsVotingEnabled = p.ChannelPointsVoting
+	if p.ChannelPointsVoting {
+		body.ChannelPointsPerVote = p.ChannelPointsPerVote
+	}
+	p.mu.Unlock()
+
+	var response struct {
+		Data []helixPoll `json:"data"`
+	}
+
+	err = p.helix.Post(ctx, Endpoint, nil, body, &response)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.creatingPoll = false
+
+	if err != nil {
+		return fmt.Errorf("failed to create twitch poll: %w", err)
+	}
+
+	if len(response.Data) == 0 {
+		return fmt.Errorf("twitch returned no poll")
+	}
+
+	p.current = &response.Data[0]
+	p.stop = make(chan struct{})
+	go p.watch(p.current.ID, p.stop)
+
+	p.socket.BroadcastChannelEvent(p.channel, websocket.PollStartEvent, p.resultsLocked())
+
+	return nil
+}
+
+// HandleVote is not supported, viewers vote through the native Twitch UI
+func (p *twitchPoll) HandleVote(username string, vote int) error {
+	return ErrNotSupported
+}
+
+func (p *twitchPoll) Results() Results {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.resultsLocked()
+}
+
+func (p *twitchPoll) EndPoll(ctx context.Context) (Results, error) {
+	p.mu.Lock()
+	if p.current == nil || p.current.Status != "ACTIVE" {
+		p.mu.Unlock()
+		return Results{}, ErrPollNotStarted
+	}
+	id := p.current.ID
+	p.mu.Unlock()
+
+	broadcasterID, err := p.broadcasterID(ctx)
+	if err != nil {
+		return Results{}, err
+	}
+
+	body := struct {
+		BroadcasterID string `json:"broadcaster_id"`
+		ID            string `json:"id"`
+		Status        string `json:"status"`
+	}{
+		BroadcasterID: broadcasterID,
+		ID:            id,
+		Status:        "TERMINATED",
+	}
+
+	var response struct {
+		Data []helixPoll `json:"data"`
+	}
+
+	if err := p.helix.Patch(ctx, Endpoint, nil, body, &response); err != nil {
+		return Results{}, fmt.Errorf("failed to end twitch poll: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The watcher may have seen the poll end in the meantime and stored it already
+	if p.current == nil || p.current.ID != id {
+		return Results{}, ErrPollNotStarted
+	}
+
+	if len(response.Data) > 0 {
+		p.current = &response.Data[0]
+	}
+
+	return p.finishLocked()
+}
+
+func (p *twitchPoll) Active() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.creatingPoll || p.creatingPrediction ||
+		(p.current != nil && p.current.Status == "ACTIVE") ||
+		(p.prediction != nil && (p.prediction.Status == "ACTIVE" || p.prediction.Status == "LOCKED"))
+}
+
+// watch refreshes the poll until it is no longer active on Twitch
+func (p *twitchPoll) watch(id string, stop chan struct{}) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), refreshInterval)
+		poll, err := p.fetchPoll(ctx, id)
+		cancel()
+		if err != nil {
+			log.Printf("Failed to refresh twitch poll: %v", err)
+			continue
+		}
+
+		p.mu.Lock()
+		if p.current == nil || p.current.ID != id {
+			p.mu.Unlock()
+			return
+		}
+
+		p.current = poll
+		if poll.Status == "ACTIVE" {
+			p.socket.BroadcastChannelEvent(p.channel, websocket.PollUpdateEvent, p.resultsLocked())
+			p.mu.Unlock()
+			continue
+		}
+
+		if _, err := p.finishLocked(); err != nil {
+			log.Printf("Failed to save twitch poll: %v", err)
+		}
+		p.mu.Unlock()
+		return
+	}
+}
+
+func (p *twitchPoll) fetchPoll(ctx context.Context, id string) (*helixPoll, error) {
+	broadcasterID, err := p.broadcasterID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("broadcaster_id", broadcasterID)
+	query.Set("id", id)
+
+	var response struct {
+		Data []helixPoll `json:"data"`
+	}
+
+	if err := p.helix.Get(ctx, Endpoint, query, &response); err != nil {
+		return nil, err
+	}
+
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("no poll found with id: %s", id)
+	}
+
+	return &response.Data[0], nil
+}
+
+// finishLocked stops watching the current poll, broadcasts and stores its results
+func (p *twitchPoll) finishLocked() (Results, error) {
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+
+	results := p.resultsLocked()
+	results.Active = false
+	p.current = nil
+
+	p.socket.BroadcastChannelEvent(p.channel, websocket.PollEndEvent, results)
+
+	return results, saveResults(p.db, p.channel, results)
+}
+
+func (p *twitchPoll) resultsLocked() Results {
+	results := Results{Mode: ModeTwitch}
+	if p.current == nil {
+		return results
+	}
+
+	results.Active = p.current.Status == "ACTIVE"
+	results.Question = p.current.Title
+	results.Duration = p.current.Duration
+	results.StartedAt = p.current.StartedAt
+	results.EndsAt = p.current.StartedAt.Add(time.Duration(p.current.Duration) * time.Second)
+
+	results.Options = make([]models.PollOption, len(p.current.Choices))
+	for i, choice := range p.current.Choices {
+		results.Options[i] = models.PollOption{Title: choice.Title, Votes: choice.Votes}
+		results.TotalVotes += choice.Votes
+	}
+
+	return results
+}
+
+func (p *twitchPoll) StartPrediction(ctx context.Context, title string, outcomes []string, window int) error {
+	title, outcomes, err := cleanPoll(title, outcomes, predictionMinOutcomes, predictionMaxOutcomes)
+	if err != nil {
+		return err
+	}
+
+	if window < predictionMinWindow || window > predictionMaxWindow {
+		return ErrInvalidDuration
+	}
+
+	broadcasterID, err := p.broadcasterID(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	if p.creatingPrediction || p.prediction != nil && (p.prediction.Status == "ACTIVE" || p.prediction.Status == "LOCKED") {
+		p.mu.Unlock()
+		return ErrPollInProgress
+	}
+	p.creatingPrediction = true
+	p.mu.Unlock()
+
+	type outcome struct {
+		Title string `json:"title"`
+	}
+
+	body := struct {
+		BroadcasterID    string    `json:"broadcaster_id"`
+		Title            string    `json:"title"`
+		Outcomes         []outcome `json:"outcomes"`
+		PredictionWindow int       `json:"prediction_window"`
+	}{
+		BroadcasterID:    broadcasterID,
+		Title:            title,
+		PredictionWindow: window,
+	}
+
+	for _, o := range outcomes {
+		body.Outcomes = append(body.Outcomes, outcome{Title: o})
+	}
+
+	var response struct {
+		Data []helixPrediction `json:"data"`
+	}
+
+	err = p.helix.Post(ctx, PredictionsEndpoint, nil, body, &response)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.creatingPrediction = false
+
+	if err != nil {
+		return fmt.Errorf("failed to create twitch prediction: %w", err)
+	}
+
+	if len(response.Data) == 0 {
+		return fmt.Errorf("twitch returned no prediction")
+	}
+
+	p.prediction = &response.Data[0]
+	p.socket.BroadcastChannelEvent(p.channel, websocket.PredictionStartEvent, toPrediction(p.prediction))
+
+	return nil
+}
+
+func (p *twitchPoll) EndPrediction(ctx context.Context, winner int) (Prediction, error) {
+	p.mu.Lock()
+	if p.prediction == nil || (p.prediction.Status != "ACTIVE" && p.prediction.Status != "LOCKED") {
+		p.mu.Unlock()
+		return Prediction{}, ErrPredictionNotStarted
+	}
+
+	if winner < 0 || winner > len(p.prediction.Outcomes) {
+		p.mu.Unlock()
+		return Prediction{}, ErrInvalidOption
+	}
+
+	body := struct {
+		BroadcasterID    string `json:"broadcaster_id"`
+		ID               string `json:"id"`
+		Status           string `json:"status"`
+		WinningOutcomeID string `json:"winning_outcome_id,omitempty"`
+	}{
+		ID:     p.prediction.ID,
+		Status: "CANCELED",
+	}
+
+	if winner > 0 {
+		body.Status = "RESOLVED"
+		body.WinningOutcomeID = p.prediction.Outcomes[winner-1].ID
+	}
+	p.mu.Unlock()
+
+	broadcasterID, err := p.broadcasterID(ctx)
+	if err != nil {
+		return Prediction{}, err
+	}
+	body.BroadcasterID = broadcasterID
+
+	var response struct {
+		Data []helixPrediction `json:"data"`
+	}
+
+	if err := p.helix.Patch(ctx, PredictionsEndpoint, nil, body, &response); err != nil {
+		return Prediction{}, fmt.Errorf("failed to end twitch prediction: %w", err)
+	}
+
+	if len(response.Data) == 0 {
+		return Prediction{}, fmt.Errorf("twitch returned no prediction")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ended := &response.Data[0]
+	if p.prediction == nil || p.prediction.ID == ended.ID {
+		p.prediction = ended
+	}
+
+	prediction := toPrediction(ended)
+	p.socket.BroadcastChannelEvent(p.channel, websocket.PredictionEndEvent, prediction)
+
+	return prediction, nil
+}
+
+// Prediction fetches the latest prediction of the channel from Twitch
+func (p *twitchPoll) Prediction(ctx context.Context) (Prediction, error) {
+	broadcasterID, err := p.broadcasterID(ctx)
+	if err != nil {
+		return Prediction{}, err
+	}
+
+	query := url.Values{}
+	query.Set("broadcaster_id", broadcasterID)
+	query.Set("first", "1")
+
+	var response struct {
+		Data []helixPrediction `json:"data"`
+	}
+
+	if err := p.helix.Get(ctx, PredictionsEndpoint, query, &response); err != nil {
+		return Prediction{}, fmt.Errorf("failed to fetch twitch prediction: %w", err)
+	}
+
+	if len(response.Data) == 0 {
+		return Prediction{}, ErrPredictionNotStarted
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A prediction being created isn't replaced by the previous one Twitch still returns
+	if !p.creatingPrediction {
+		p.prediction = &response.Data[0]
+	}
+
+	return toPrediction(&response.Data[0]), nil
+}
+
+func toPrediction(hp *helixPrediction) Prediction {
+	prediction := Prediction{
+		ID:               hp.ID,
+		Title:            hp.Title,
+		Status:           hp.Status,
+		WinningOutcomeID: hp.WinningOutcomeID,
+		WindowSeconds:    hp.PredictionWindow,
+		CreatedAt:        hp.CreatedAt,
+		Outcomes:         make([]PredictionOutcome, len(hp.Outcomes)),
+	}
+
+	for i, o := range hp.Outcomes {
+		prediction.Outcomes[i] = PredictionOutcome{
+			ID:            o.ID,
+			Title:         o.Title,
+			Users:         o.Users,
+			ChannelPoints: o.ChannelPoints,
+		}
+	}
+
+	return prediction
+}
+
+func (p *twitchPoll) broadcasterID(ctx context.Context) (string, error) {
+	id, err := p.helix.GetUserID(ctx, p.channel)
+	if err != nil {
+		return "", fmt.Errorf("failed to get broadcaster ID: %w", err)
+	}
+
+	return id, nil
+}
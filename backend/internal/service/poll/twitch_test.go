@@ -0,0 +1,374 @@
+package poll
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"twitch-client/internal/credentials"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+)
+
+const (
+	activePoll = `{"data":[{"id":"poll-1","title":"Best map?","status":"ACTIVE","duration":60,
+		"started_at":"2024-01-01T20:00:00Z","choices":[{"id":"a","title":"Dust","votes":0},{"id":"b","title":"Mirage","votes":0}]}]}`
+	terminatedPoll = `{"data":[{"id":"poll-1","title":"Best map?","status":"TERMINATED","duration":60,
+		"started_at":"2024-01-01T20:00:00Z","choices":[{"id":"a","title":"Dust","votes":3},{"id":"b","title":"Mirage","votes":4}]}]}`
+	activePrediction = `{"data":[{"id":"pred-1","title":"Win?","status":"ACTIVE","prediction_window":120,
+		"outcomes":[{"id":"yes","title":"Yes","users":0},{"id":"no","title":"No","users":0}]}]}`
+	resolvedPrediction = `{"data":[{"id":"pred-1","title":"Win?","status":"RESOLVED","winning_outcome_id":"yes","prediction_window":120,
+		"outcomes":[{"id":"yes","title":"Yes","users":5,"channel_points":500},{"id":"no","title":"No","users":2,"channel_points":100}]}]}`
+	users = `{"data":[{"id":"1234","login":"streamer"}]}`
+)
+
+// helixRoute is the canned answer of the fake Helix server to "METHOD /path"
+type helixRoute struct {
+	status int
+	body   string
+}
+
+type helixRequest struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+type fakeHelix struct {
+	routes   map[string]helixRoute
+	requests []helixRequest
+	mu       sync.Mutex
+}
+
+func (f *fakeHelix) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		json.Unmarshal(data, &body)
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, helixRequest{method: r.Method, path: r.URL.Path, body: body})
+	route, ok := f.routes[r.Method+" "+r.URL.Path]
+	f.mu.Unlock()
+
+	if !ok {
+		route = helixRoute{status: http.StatusNotFound, body: `{"error":"Not Found","status":404,"message":"no route"}`}
+	}
+
+	// Let the client retry a 429 right away instead of waiting a minute for the bucket
+	w.Header().Set("Ratelimit-Limit", "800")
+	w.Header().Set("Ratelimit-Remaining", "799")
+	w.Header().Set("Ratelimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+	w.WriteHeader(route.status)
+	io.WriteString(w, route.body)
+}
+
+// last returns the last request sent to "METHOD /path"
+func (f *fakeHelix) last(method, path string) (helixRequest, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := len(f.requests) - 1; i >= 0; i-- {
+		if f.requests[i].method == method && f.requests[i].path == path {
+			return f.requests[i], true
+		}
+	}
+	return helixRequest{}, false
+}
+
+func (f *fakeHelix) count(method, path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, r := range f.requests {
+		if r.method == method && r.path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestTwitchPoll(t *testing.T, routes map[string]helixRoute) (*twitchPoll, *fakeHelix, *fakeStore) {
+	t.Helper()
+
+	fake := &fakeHelix{routes: routes}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	creds := credentials.NewCredentialsManager()
+	creds.Set("client-id", "token")
+
+	socket, store := newTestDeps(t)
+	p := newTwitchPoll(store, socket, helix.NewClient(creds, server.URL), "streamer")
+	t.Cleanup(func() {
+		p.mu.Lock()
+		if p.stop != nil {
+			close(p.stop)
+			p.stop = nil
+		}
+		p.mu.Unlock()
+	})
+
+	return p, fake, store
+}
+
+func TestTwitchPoll(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes map[string]helixRoute
+		run    func(ctx context.Context, p *twitchPoll) (interface{}, error)
+		check  func(t *testing.T, result interface{}, fake *fakeHelix, store *fakeStore)
+	}{
+		{
+			name: "start poll",
+			routes: map[string]helixRoute{
+				"GET /users":  {http.StatusOK, users},
+				"POST /polls": {http.StatusOK, activePoll},
+			},
+			run: func(ctx context.Context, p *twitchPoll) (interface{}, error) {
+				if err := p.StartPoll(ctx, " Best map? ", []string{"Dust", " ", "Mirage"}, 60); err != nil {
+					return nil, err
+				}
+				return p.Results(), nil
+			},
+			check: func(t *testing.T, result interface{}, fake *fakeHelix, store *fakeStore) {
+				results := result.(Results)
+				if !results.Active || results.Question != "Best map?" || len(results.Options) != 2 {
+					t.Errorf("unexpected results: %+v", results)
+				}
+
+				req, _ := fake.last(http.MethodPost, "/polls")
+				if req.body["broadcaster_id"] != "1234" || req.body["title"] != "Best map?" {
+					t.Errorf("unexpected request body: %v", req.body)
+				}
+				if choices, _ := req.body["choices"].([]interface{}); len(choices) != 2 {
+					t.Errorf("expected the empty option to be dropped, got %v", req.body["choices"])
+				}
+			},
+		},
+		{
+			name: "end poll",
+			routes: map[string]helixRoute{
+				"GET /users":   {http.StatusOK, users},
+				"POST /polls":  {http.StatusOK, activePoll},
+				"PATCH /polls": {http.StatusOK, terminatedPoll},
+			},
+			run: func(ctx context.Context, p *twitchPoll) (interface{}, error) {
+				if err := p.StartPoll(ctx, "Best map?", []string{"Dust", "Mirage"}, 60); err != nil {
+					return nil, err
+				}
+				return p.EndPoll(ctx)
+			},
+			check: func(t *testing.T, result interface{}, fake *fakeHelix, store *fakeStore) {
+				results := result.(Results)
+				if results.Active || results.TotalVotes != 7 || results.Options[1].Votes != 4 {
+					t.Errorf("unexpected results: %+v", results)
+				}
+
+				req, _ := fake.last(http.MethodPatch, "/polls")
+				if req.body["id"] != "poll-1" || req.body["status"] != "TERMINATED" {
+					t.Errorf("unexpected request body: %v", req.body)
+				}
+				if store.inserts() != 1 {
+					t.Errorf("expected the poll to be stored once, got %d", store.inserts())
+				}
+			},
+		},
+		{
+			name: "fetch poll",
+			routes: map[string]helixRoute{
+				"GET /users": {http.StatusOK, users},
+				"GET /polls": {http.StatusOK, terminatedPoll},
+			},
+			run: func(ctx context.Context, p *twitchPoll) (interface{}, error) {
+				return p.fetchPoll(ctx, "poll-1")
+			},
+			check: func(t *testing.T, result interface{}, fake *fakeHelix, store *fakeStore) {
+				poll := result.(*helixPoll)
+				if poll.ID != "poll-1" || poll.Status != "TERMINATED" || poll.Choices[0].Votes != 3 {
+					t.Errorf("unexpected poll: %+v", poll)
+				}
+			},
+		},
+		{
+			name: "start prediction",
+			routes: map[string]helixRoute{
+				"GET /users":        {http.StatusOK, users},
+				"POST /predictions": {http.StatusOK, activePrediction},
+			},
+			run: func(ctx context.Context, p *twitchPoll) (interface{}, error) {
+				if err := p.StartPrediction(ctx, "Win?", []string{"Yes", "No"}, 120); err != nil {
+					return nil, err
+				}
+				return p.Active(), nil
+			},
+			check: func(t *testing.T, result interface{}, fake *fakeHelix, store *fakeStore) {
+				if !result.(bool) {
+					t.Error("expected the prediction to be active")
+				}
+
+				req, _ := fake.last(http.MethodPost, "/predictions")
+				if req.body["prediction_window"] != float64(120) {
+					t.Errorf("unexpected request body: %v", req.body)
+				}
+			},
+		},
+		{
+			name: "end prediction",
+			routes: map[string]helixRoute{
+				"GET /users":         {http.StatusOK, users},
+				"POST /predictions":  {http.StatusOK, activePrediction},
+				"PATCH /predictions": {http.StatusOK, resolvedPrediction},
+			},
+			run: func(ctx context.Context, p *twitchPoll) (interface{}, error) {
+				if err := p.StartPrediction(ctx, "Win?", []string{"Yes", "No"}, 120); err != nil {
+					return nil, err
+				}
+				return p.EndPrediction(ctx, 1)
+			},
+			check: func(t *testing.T, result interface{}, fake *fakeHelix, store *fakeStore) {
+				prediction := result.(Prediction)
+				if prediction.Status != "RESOLVED" || prediction.WinningOutcomeID != "yes" {
+					t.Errorf("unexpected prediction: %+v", prediction)
+				}
+
+				req, _ := fake.last(http.MethodPatch, "/predictions")
+				if req.body["status"] != "RESOLVED" || req.body["winning_outcome_id"] != "yes" {
+					t.Errorf("unexpected request body: %v", req.body)
+				}
+			},
+		},
+		{
+			name: "fetch prediction",
+			routes: map[string]helixRoute{
+				"GET /users":       {http.StatusOK, users},
+				"GET /predictions": {http.StatusOK, resolvedPrediction},
+			},
+			run: func(ctx context.Context, p *twitchPoll) (interface{}, error) {
+				return p.Prediction(ctx)
+			},
+			check: func(t *testing.T, result interface{}, fake *fakeHelix, store *fakeStore) {
+				prediction := result.(Prediction)
+				if prediction.ID != "pred-1" || prediction.Outcomes[0].ChannelPoints != 500 {
+					t.Errorf("unexpected prediction: %+v", prediction)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, fake, store := newTestTwitchPoll(t, tt.routes)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := tt.run(ctx, p)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, result, fake, store)
+		})
+	}
+}
+
+func TestTwitchPollErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		want     error
+		requests int
+	}{
+		{"unauthorized", http.StatusUnauthorized, helix.ErrUnauthorized, 1},
+		{"not found", http.StatusNotFound, helix.ErrNotFound, 1},
+		// The first attempt and three retries after the bucket resets
+		{"rate limited", http.StatusTooManyRequests, helix.ErrRateLimited, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, fake, _ := newTestTwitchPoll(t, map[string]helixRoute{
+				"GET /users":  {http.StatusOK, users},
+				"POST /polls": {tt.status, `{"error":"error","status":` + strconv.Itoa(tt.status) + `,"message":"nope"}`},
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err := p.StartPoll(ctx, "Best map?", []string{"Dust", "Mirage"}, 60)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+
+			var apiErr *helix.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status || apiErr.Message != "nope" {
+				t.Errorf("expected an APIError with status %d, got %v", tt.status, err)
+			}
+			if n := fake.count(http.MethodPost, "/polls"); n != tt.requests {
+				t.Errorf("expected %d requests, got %d", tt.requests, n)
+			}
+			if p.Active() {
+				t.Error("a failed start must not leave the poll active")
+			}
+		})
+	}
+}
+
+func TestTwitchPollStartWhileActive(t *testing.T) {
+	p, _, _ := newTestTwitchPoll(t, map[string]helixRoute{
+		"GET /users":  {http.StatusOK, users},
+		"POST /polls": {http.StatusOK, activePoll},
+	})
+
+	ctx := context.Background()
+	if err := p.StartPoll(ctx, "Best map?", []string{"Dust", "Mirage"}, 60); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.StartPoll(ctx, "Again?", []string{"Yes", "No"}, 60); !errors.Is(err, ErrPollInProgress) {
+		t.Errorf("expected ErrPollInProgress, got %v", err)
+	}
+	if _, err := p.EndPrediction(ctx, 0); !errors.Is(err, ErrPredictionNotStarted) {
+		t.Errorf("expected ErrPredictionNotStarted, got %v", err)
+	}
+}
+
+// fakeStore keeps the poll history in memory
+type fakeStore struct {
+	polls []models.Poll
+	mu    sync.Mutex
+}
+
+func (s *fakeStore) CreatePoll(poll *models.Poll) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	poll.ID = len(s.polls) + 1
+	s.polls = append(s.polls, *poll)
+	return nil
+}
+
+func (s *fakeStore) GetPolls(channel string, limit int) ([]models.Poll, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var polls []models.Poll
+	for i := len(s.polls) - 1; i >= 0 && len(polls) < limit; i-- {
+		if s.polls[i].Channel == channel {
+			polls = append(polls, s.polls[i])
+		}
+	}
+	return polls, nil
+}
+
+func (s *fakeStore) inserts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.polls)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+	"twitch-client/internal/automod"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+)
+
+// A raid's revert is stored with this deadline in case the raid never ends
+// here, UnprotectChannel moves it up once it does
+const raidRevertLimit = 6 * time.Hour
+
+// ProtectChannel switches a channel into the protective mode of its raid
+// protection. Switching back is stored like a chat settings revert, so it
+// survives restarts and is retried when it fails.
+func (s *Service) ProtectChannel(settings models.RaidProtection) error {
+	ctx, cancel := context.WithTimeout(context.Background(), enforceTimeout)
+	defer cancel()
+
+	broadcasterID, moderatorID, err := s.chatSettingsIDs(ctx, settings.Channel)
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.botHelix.GetChatSettings(ctx, broadcasterID, moderatorID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat settings: %w", err)
+	}
+
+	enabled := true
+	var update helix.ChatSettingsUpdate
+	switch settings.Mode {
+	case models.RaidModeEmote:
+		update.EmoteMode = &enabled
+	default:
+		duration := settings.FollowersDurationMinutes
+		update.FollowerMode = &enabled
+		update.FollowerModeDuration = &duration
+	}
+
+	// The reverts are stored first, so the mode is never on without one
+	var reverts []models.ChatSettingsRevert
+	for mode, modeUpdate := range update.Modes() {
+		data, err := json.Marshal(previous.Revert(modeUpdate))
+		if err != nil {
+			return fmt.Errorf("failed to encode revert: %w", err)
+		}
+
+		revert := models.ChatSettingsRevert{
+			Channel:  settings.Channel,
+			Mode:     mode,
+			Settings: data,
+			RevertAt: time.Now().Add(raidRevertLimit),
+			Raid:     true,
+		}
+		if err := s.db.SaveChatSettingsRevert(revert); err != nil {
+			return fmt.Errorf("failed to save revert: %w", err)
+		}
+		reverts = append(reverts, revert)
+	}
+
+	if _, err := s.botHelix.UpdateChatSettings(ctx, broadcasterID, moderatorID, update); err != nil {
+		for _, revert := range reverts {
+			s.cancelRevert(revert.Channel, revert.Mode)
+		}
+		return fmt.Errorf("failed to update chat settings: %w", err)
+	}
+	log.Printf("Switched %s into %s-only mode", settings.Channel, settings.Mode)
+
+	for _, revert := range reverts {
+		s.scheduleRevert(revert)
+	}
+
+	return nil
+}
+
+// UnprotectChannel switches the modes raid protection changed back now
+func (s *Service) UnprotectChannel(channel string) {
+	reverts, err := s.db.GetChatSettingsReverts(channel)
+	if err != nil {
+		log.Printf("Failed to get pending reverts of %s: %v", channel, err)
+		return
+	}
+
+	for _, revert := range reverts {
+		if revert.Raid {
+			s.revertNow(revert)
+		}
+	}
+}
+
+func (s *Service) GetRaidProtection(channel string) (models.RaidProtection, error) {
+	return s.db.GetRaidProtection(channel)
+}
+
+func (s *Service) SaveRaidProtection(settings *models.RaidProtection) error {
+	if err := automod.ValidateRaidProtection(*settings); err != nil {
+		return err
+	}
+
+	if err := s.db.SaveRaidProtection(settings); err != nil {
+		return fmt.Errorf("failed to save raid protection: %w", err)
+	}
+
+	return s.raidGuard.Reload()
+}
+
+// GetRaidStatus returns the ongoing raid of a channel
+func (s *Service) GetRaidStatus(channel string) (automod.Raid, bool) {
+	return s.raidGuard.Status(channel)
+}
@@ -1,24 +1,30 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
+	"net/url"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"twitch-client/internal/automod"
 	"twitch-client/internal/bot"
+	"twitch-client/internal/bot/template"
 	"twitch-client/internal/client"
 	"twitch-client/internal/config"
 	"twitch-client/internal/credentials"
 	"twitch-client/internal/db"
 	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+	"twitch-client/internal/roles"
+	"twitch-client/internal/server/websocket"
+	"twitch-client/internal/service/poll"
+	"twitch-client/internal/timers"
 	"twitch-client/internal/trends"
-
-	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type EmoteResponse struct {
@@ -54,6 +60,21 @@ type UserInfo struct {
 	CreatedAt       time.Time `json:"created_at"`
 }
 
+// UserTimeline is everything known about a chatter, for moderators to look at before they act
+type UserTimeline struct {
+	User              UserInfo                  `json:"user"`
+	FirstSeen         *time.Time                `json:"first_seen"`
+	LastSeen          *time.Time                `json:"last_seen"`
+	MessageCount      int                       `json:"message_count"`
+	RecentMessages    []models.ChatMessage      `json:"recent_messages"`
+	ModerationActions []models.ModerationAction `json:"moderation_actions"`
+}
+
+const (
+	timelineMessages = 50
+	timelineActions  = 50
+)
+
 type UserInfoResponse struct {
 	Data []UserInfo `json:"data"`
 }
@@ -85,149 +106,196 @@ type StreamInfoResponse struct {
 
 type Service struct {
 	twitchClient *client.Client
-	trendTracker *trends.TrendTracker
+	trends       *trends.ChannelTrends
 	config       *config.Config
 	db           *db.Database
-	credentials  *credentials.Credentials
-	bot          *bot.Bot
+	accounts     *credentials.Accounts
+	// Helix calls are routed by role: the bot moderates, the broadcaster manages the channel
+	botHelix         *helix.Client
+	broadcasterHelix *helix.Client
+	bot              *bot.Bot
+	polls            *poll.Manager
+	automod          *automod.Engine
+	linkGuard        *automod.LinkGuard
+	raidGuard        *automod.RaidGuard
+	timers           *timers.Scheduler
+	socket           *websocket.WebSocket
+
+	// Scheduled chat mode reverts by channel/mode
+	reverts   map[string]*time.Timer
+	revertsMu sync.Mutex
 }
 
-func NewService(twitchClient *client.Client, trendTracker *trends.TrendTracker, cfg *config.Config, db *db.Database, creds *credentials.Credentials, b *bot.Bot) *Service {
+func NewService(twitchClient *client.Client, channelTrends *trends.ChannelTrends, cfg *config.Config, db *db.Database, accounts *credentials.Accounts, botHelix, broadcasterHelix *helix.Client, b *bot.Bot, polls *poll.Manager, automod *automod.Engine, linkGuard *automod.LinkGuard, raidGuard *automod.RaidGuard, timers *timers.Scheduler, socket *websocket.WebSocket) *Service {
 	svc := &Service{
-		twitchClient: twitchClient,
-		trendTracker: trendTracker,
-		config:       cfg,
-		db:           db,
-		credentials:  creds,
-		bot:          b,
+		twitchClient:     twitchClient,
+		trends:           channelTrends,
+		config:           cfg,
+		db:               db,
+		accounts:         accounts,
+		botHelix:         botHelix,
+		broadcasterHelix: broadcasterHelix,
+		bot:              b,
+		polls:            polls,
+		automod:          automod,
+		linkGuard:        linkGuard,
+		raidGuard:        raidGuard,
+		timers:           timers,
+		socket:           socket,
+		reverts:          make(map[string]*time.Timer),
 	}
 
 	return svc
 }
 
-func (s *Service) InterceptMessage(message twitch.PrivateMessage) {
-	if message.FirstMessage && strings.Contains(message.Message, "remove the space") {
-		// ban the guy
-		s.BanUser(message.User.Name)
+func (s *Service) GetUserInfo(ctx context.Context, username string) (UserInfo, error) {
+	var response UserInfoResponse
+	if err := s.botHelix.Get(ctx, "/users", url.Values{"login": {username}}, &response); err != nil {
+		return UserInfo{}, fmt.Errorf("failed to get user info: %w", err)
 	}
 
-	// add more message interceptors here
-}
-
-func (s *Service) BanUser(username string) error {
-	clientID, oauthToken, err := s.credentials.Get()
-	if err != nil {
-		return fmt.Errorf("failed to get credentials: %w", err)
+	if len(response.Data) == 0 {
+		return UserInfo{}, fmt.Errorf("no user found with login: %s", username)
 	}
 
-	broadcasterID, err := s.GetBroadcasterID(s.twitchClient.GetCurrentChannel())
-	if err != nil {
-		return fmt.Errorf("failed to get broadcaster ID: %w", err)
-	}
+	return response.Data[0], nil
+}
 
-	userInfo, err := s.GetUserInfo(username)
+// Polls returns the poll service of a channel
+func (s *Service) Polls(channel string) poll.Service {
+	return s.polls.For(channel)
+}
 
-	query_params := fmt.Sprintf("broadcaster_id=%s&moderator_id=%s", broadcasterID, broadcasterID)
+func (s *Service) SendMessage(channel, message string) error {
+	return s.twitchClient.SendMessage(channel, message)
+}
 
-	url := fmt.Sprintf("https://api.twitch.tv/helix/moderation/bans?%s", query_params)
+func (s *Service) GetChatHistory(filter db.ChatHistoryFilter) ([]models.ChatMessage, error) {
+	return s.db.GetChatMessages(filter)
+}
 
-	body := struct {
-		UserID string `json:"user_id"`
-		Reason string `json:"reason"`
-	}{
-		UserID: userInfo.ID,
-		Reason: "Banned by bot",
-	}
+func (s *Service) SearchChat(channel, query string, limit int) ([]models.ChatMessage, error) {
+	return s.db.SearchChatMessages(channel, query, limit)
+}
 
-	jsonBody, err := json.Marshal(body)
+// GetUserTimeline gathers what a user said and what the bot did about them,
+// an empty channel covers every channel
+func (s *Service) GetUserTimeline(ctx context.Context, username, channel string) (UserTimeline, error) {
+	userInfo, err := s.GetUserInfo(ctx, username)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request body: %w", err)
+		return UserTimeline{}, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	stats, err := s.db.GetChatUserStats(channel, userInfo.ID)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return UserTimeline{}, fmt.Errorf("failed to get chat stats: %w", err)
 	}
 
-	req.Header.Set("Client-ID", clientID)
-	req.Header.Set("Authorization", "Bearer "+oauthToken)
-
-	client := &http.Client{}
+	messages, err := s.db.GetChatMessages(db.ChatHistoryFilter{
+		Channel: channel,
+		UserID:  userInfo.ID,
+		Limit:   timelineMessages,
+	})
+	if err != nil {
+		return UserTimeline{}, fmt.Errorf("failed to get recent messages: %w", err)
+	}
 
-	resp, err := client.Do(req)
+	actions, err := s.db.GetUserModerationActions(channel, userInfo.ID, timelineActions)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return UserTimeline{}, fmt.Errorf("failed to get moderation actions: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	return UserTimeline{
+		User:              userInfo,
+		FirstSeen:         stats.FirstSeen,
+		LastSeen:          stats.LastSeen,
+		MessageCount:      stats.MessageCount,
+		RecentMessages:    messages,
+		ModerationActions: actions,
+	}, nil
+}
+
+// JoinChannel makes the bot join a channel and remembers it across restarts
+func (s *Service) JoinChannel(channel string) error {
+	channel = client.NormalizeChannel(channel)
+
+	err := s.twitchClient.JoinChannel(channel)
+	if err != nil && !errors.Is(err, client.ErrBotNotValidated) {
+		return err
 	}
 
-	var response BanResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+	if err := s.db.AddChannel(channel); err != nil {
+		return fmt.Errorf("failed to save channel: %w", err)
 	}
 
 	return nil
 }
 
-func (s *Service) GetUserInfo(username string) (UserInfo, error) {
-	clientID, oauthToken, err := s.credentials.Get()
-	if err != nil {
-		return UserInfo{}, fmt.Errorf("failed to get credentials: %w", err)
-	}
+// PartChannel makes the bot leave a channel and drops its trends, polls, raid state and timer line counts
+func (s *Service) PartChannel(channel string) error {
+	channel = client.NormalizeChannel(channel)
 
-	// get the user_id based on username to ban
-	url := fmt.Sprintf("https://api.twitch.tv/helix/users?login=%s", username)
+	if err := s.twitchClient.PartChannel(channel); err != nil {
+		return err
+	}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return UserInfo{}, fmt.Errorf("failed to create request: %w", err)
+	if err := s.db.RemoveChannel(channel); err != nil {
+		return fmt.Errorf("failed to remove channel: %w", err)
 	}
 
-	req.Header.Set("Client-Id", clientID)
-	req.Header.Set("Authorization", "Bearer "+oauthToken)
+	s.trends.Remove(channel)
+	s.polls.Remove(channel)
+	s.raidGuard.Remove(channel)
+	s.timers.Remove(channel)
 
-	client := &http.Client{}
+	return nil
+}
 
-	resp, err := client.Do(req)
+// RestoreChannels joins the channels the bot was in before the restart
+func (s *Service) RestoreChannels() error {
+	channels, err := s.db.GetChannels()
 	if err != nil {
-		return UserInfo{}, fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to get channels: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return UserInfo{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	var response UserInfoResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return UserInfo{}, fmt.Errorf("failed to decode response: %w", err)
+	for _, channel := range channels {
+		err := s.twitchClient.JoinChannel(channel)
+		if err != nil && !errors.Is(err, client.ErrBotNotValidated) {
+			log.Printf("Failed to join channel %s: %v", channel, err)
+		}
 	}
 
-	if len(response.Data) == 0 {
-		return UserInfo{}, fmt.Errorf("no user found with login: %s", username)
-	}
+	return nil
+}
 
-	return response.Data[0], nil
+func (s *Service) GetChannels() []string {
+	return s.twitchClient.Channels()
 }
 
-func (s *Service) SendMessage(message string) error {
-	return s.twitchClient.SendMessage(message)
+func (s *Service) HasChannel(channel string) bool {
+	return s.twitchClient.HasChannel(channel)
 }
 
-func (s *Service) SetChannel(channelName string) error {
-	return s.twitchClient.Connect(channelName)
+// Status tells whether the bot is really in chat
+type Status struct {
+	Connection client.Status `json:"connection"`
+	Login      string        `json:"login"`
+	Channels   []string      `json:"channels"`
 }
 
-func (s *Service) GetCurrentChannel() string {
-	return s.twitchClient.GetCurrentChannel()
+func (s *Service) GetStatus() Status {
+	login, _ := s.accounts.Bot.Identity()
+	return Status{
+		Connection: s.twitchClient.Status(),
+		Login:      login,
+		Channels:   s.twitchClient.Channels(),
+	}
 }
 
-func (s *Service) GetTrends() (emotesResp []EmoteResponse, phrasesResp []PhraseResponse) {
-	topEmotes := s.trendTracker.GetTopEmotes(10)
-	topPhrases := s.trendTracker.GetTopPhrases(10)
+func (s *Service) GetTrends(channel string) (emotesResp []EmoteResponse, phrasesResp []PhraseResponse) {
+	trendTracker := s.trends.For(channel)
+	topEmotes := trendTracker.GetTopEmotes(10)
+	topPhrases := trendTracker.GetTopPhrases(10)
 
 	emotesResp = make([]EmoteResponse, len(topEmotes))
 	for i, item := range topEmotes {
@@ -250,49 +318,18 @@ func (s *Service) GetTrends() (emotesResp []EmoteResponse, phrasesResp []PhraseR
 	return emotesResp, phrasesResp
 }
 
-func (s *Service) GetTopUsers() []trends.UserEngagement {
-	topUsers := s.trendTracker.GetTopUsers(10)
+func (s *Service) GetTopUsers(channel string) []trends.UserEngagement {
+	topUsers := s.trends.For(channel).GetTopUsers(10)
 
 	return topUsers
 }
 
-func (s *Service) GetStreamInfo(channelName string) (StreamInfo, error) {
-	clientID, oauthToken, err := s.credentials.Get()
-	if err != nil {
-		return StreamInfo{}, fmt.Errorf("failed to get credentials: %w", err)
-	}
-
-	// Create the request URL
+func (s *Service) GetStreamInfo(ctx context.Context, channelName string) (StreamInfo, error) {
 	log.Printf("Fetching info for channel: %s\n", channelName)
-	url := fmt.Sprintf("https://api.twitch.tv/helix/streams?user_login=%s", channelName)
-
-	// Create a new GET request
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return StreamInfo{}, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set required headers
-	req.Header.Set("Client-ID", clientID)
-	req.Header.Set("Authorization", "Bearer "+oauthToken)
 
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return StreamInfo{}, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for successful response
-	if resp.StatusCode != http.StatusOK {
-		return StreamInfo{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	// Parse the response
 	var streamResponse StreamInfoResponse
-	if err := json.NewDecoder(resp.Body).Decode(&streamResponse); err != nil {
-		return StreamInfo{}, fmt.Errorf("failed to decode response: %w", err)
+	if err := s.botHelix.Get(ctx, "/streams", url.Values{"user_login": {channelName}}, &streamResponse); err != nil {
+		return StreamInfo{}, fmt.Errorf("failed to get stream info: %w", err)
 	}
 
 	// Check if we got any data
@@ -304,10 +341,7 @@ func (s *Service) GetStreamInfo(channelName string) (StreamInfo, error) {
 	return streamResponse.Data[0], nil
 }
 
-func (s *Service) UpdateStreamInfo(broadcasterID, title, gameID string, tags []string) error {
-	url := fmt.Sprintf("https://api.twitch.tv/helix/channels?broadcaster_id=%s", broadcasterID)
-
-	// Prepare request body
+func (s *Service) UpdateStreamInfo(ctx context.Context, broadcasterID, title, gameID string, tags []string) error {
 	body := struct {
 		Title  string   `json:"title,omitempty"`
 		GameID string   `json:"game_id,omitempty"`
@@ -318,107 +352,167 @@ func (s *Service) UpdateStreamInfo(broadcasterID, title, gameID string, tags []s
 		Tags:   tags,
 	}
 
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request body: %w", err)
+	if err := s.broadcasterHelix.Patch(ctx, "/channels", url.Values{"broadcaster_id": {broadcasterID}}, body, nil); err != nil {
+		return fmt.Errorf("failed to update stream info: %w", err)
 	}
 
-	// Create request
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	return nil
+}
+
+func (s *Service) GetBroadcasterID(ctx context.Context, username string) (string, error) {
+	return s.botHelix.GetUserID(ctx, username)
+}
+
+// botUserID returns the user ID of the bot account, it acts as the moderator in Helix calls
+func (s *Service) botUserID() (string, error) {
+	_, userID := s.accounts.Bot.Identity()
+	if userID == "" {
+		return "", fmt.Errorf("bot account is not logged in or its token was not validated yet")
 	}
+	return userID, nil
+}
 
-	// Set headers
-	req.Header.Set("Client-ID", s.config.TwitchClientID)
-	req.Header.Set("Authorization", "Bearer "+s.config.TwitchOAuthToken)
-	req.Header.Set("Content-Type", "application/json")
+// CommandInfo is a command along with how it is used in a channel
+type CommandInfo struct {
+	models.Command
+	Usage db.CommandUsage `json:"usage"`
+}
+
+// Number of most frequent users listed per command
+const commandTopUsers = 5
 
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func (s *Service) GetAllCommands(channel string) ([]CommandInfo, error) {
+	commands, err := s.bot.GetAllCommands(channel)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Check response
-	if resp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	usage, err := s.db.GetCommandUsage(channel, commandTopUsers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get command usage: %w", err)
 	}
 
-	return nil
+	infos := make([]CommandInfo, len(commands))
+	for i, cmd := range commands {
+		infos[i] = CommandInfo{Command: cmd, Usage: db.CommandUsage{TopUsers: []db.CommandUser{}}}
+		if u, ok := usage[cmd.Name]; ok {
+			infos[i].Usage = *u
+		}
+	}
+
+	return infos, nil
 }
 
-func (s *Service) GetBroadcasterID(username string) (string, error) {
-	url := fmt.Sprintf("https://api.twitch.tv/helix/users?login=%s", username)
+var (
+	ErrInvalidAlias           = errors.New("aliases have to be single words other than the command name")
+	ErrCommandTaken           = errors.New("another command already uses this name or alias")
+	ErrInvalidPermissionLevel = errors.New("permission level must be everyone, follower, subscriber, vip, moderator or broadcaster")
+	ErrInvalidCooldownMode    = errors.New("cooldown mode must be user, global or both")
+)
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+// AddCommand creates a command, with an empty channel the command is shared by all of them
+func (s *Service) AddCommand(cmd *models.Command) error {
+	if err := s.validateCommand(cmd); err != nil {
+		return err
 	}
 
-	req.Header.Set("Client-ID", s.config.TwitchClientID)
-	req.Header.Set("Authorization", "Bearer "+s.config.TwitchOAuthToken)
+	return s.db.CreateCommand(cmd)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// UpdateCommand changes a command, it stays in its channel
+func (s *Service) UpdateCommand(cmd *models.Command) (*models.Command, error) {
+	stored, err := s.db.GetCommand(cmd.ID)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to get command: %w", err)
+	}
+	cmd.Channel = stored.Channel
+
+	if err := s.validateCommand(cmd); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	return s.db.UpdateCommand(cmd)
+}
+
+// validateCommand checks the response template and cleans up the aliases,
+// no two commands of a channel may share a name or alias
+func (s *Service) validateCommand(cmd *models.Command) error {
+	if _, err := template.Parse(cmd.Response); err != nil {
+		return err
 	}
 
-	var response struct {
-		Data []struct {
-			ID string `json:"id"`
-		} `json:"data"`
+	if cmd.PermissionLevel == "" {
+		cmd.PermissionLevel = models.PermissionEveryone
+	}
+	if !slices.Contains(models.PermissionLevels, cmd.PermissionLevel) {
+		return ErrInvalidPermissionLevel
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	switch cmd.CooldownMode {
+	case "":
+		cmd.CooldownMode = models.CooldownPerUser
+	case models.CooldownPerUser, models.CooldownGlobal, models.CooldownBoth:
+	default:
+		return ErrInvalidCooldownMode
 	}
 
-	if len(response.Data) == 0 {
-		return "", fmt.Errorf("no user found with login: %s", username)
+	aliases := []string{}
+	seen := map[string]bool{cmd.Name: true}
+	for _, alias := range cmd.Aliases {
+		alias = strings.TrimPrefix(strings.TrimSpace(alias), "!")
+		if alias == "" || strings.ContainsAny(alias, " \t") || seen[alias] {
+			return fmt.Errorf("%w: %q", ErrInvalidAlias, alias)
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
 	}
+	cmd.Aliases = aliases
 
-	return response.Data[0].ID, nil
-}
+	taken, err := s.db.CommandNameTaken(cmd.Channel, cmd.ID, append([]string{cmd.Name}, aliases...))
+	if err != nil {
+		return fmt.Errorf("failed to check command names: %w", err)
+	}
+	if taken {
+		return ErrCommandTaken
+	}
 
-func (s *Service) GetAllCommands() ([]models.Command, error) {
-	return s.bot.GetAllCommands()
+	return nil
 }
 
-func (s *Service) AddCommand(name, description, response string, enabled bool, cooldown int) error {
-	cmd := &models.Command{
-		Name:            name,
-		Description:     description,
-		Response:        response,
-		Enabled:         enabled,
-		CooldownSeconds: cooldown,
-	}
+func (s *Service) DeleteCommand(id int) error {
+	return s.db.DeleteCommand(id)
+}
 
-	return s.db.CreateCommand(cmd)
+func (s *Service) GetDashboardUsers() ([]models.DashboardUser, error) {
+	return s.db.GetDashboardUsers()
 }
 
-func (s *Service) UpdateCommand(id int, name, description, response string, enabled bool, cooldown int) (*models.Command, error) {
-	cmd := &models.Command{
-		ID:              id,
-		Name:            name,
-		Description:     description,
-		Response:        response,
-		Enabled:         enabled,
-		CooldownSeconds: cooldown,
+// SetDashboardRole gives a Twitch user a role on the dashboard, the user is
+// looked up by login so the role survives renames
+func (s *Service) SetDashboardRole(ctx context.Context, login string, role roles.Role) (*models.DashboardUser, error) {
+	userID, err := s.botHelix.GetUserID(ctx, login)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user id: %w", err)
 	}
 
-	return s.db.UpdateCommand(cmd)
+	user := &models.DashboardUser{
+		UserID: userID,
+		Login:  strings.ToLower(login),
+		Role:   role,
+	}
+	if err := s.db.SaveDashboardUser(user); err != nil {
+		return nil, fmt.Errorf("failed to save dashboard user: %w", err)
+	}
+	s.bot.InvalidateDashboardRoles()
+
+	return user, nil
 }
 
-func (s *Service) DeleteCommand(id int) error {
-	return s.db.DeleteCommand(id)
+func (s *Service) RemoveDashboardUser(userID string) error {
+	if err := s.db.DeleteDashboardUser(userID); err != nil {
+		return err
+	}
+	s.bot.InvalidateDashboardRoles()
+
+	return nil
 }
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/timers"
+)
+
+func (s *Service) GetTimers(channel string) ([]models.Timer, error) {
+	return s.db.GetChannelTimers(channel)
+}
+
+func (s *Service) AddTimer(timer *models.Timer) error {
+	if err := timers.ValidateTimer(*timer); err != nil {
+		return err
+	}
+
+	if err := s.db.CreateTimer(timer); err != nil {
+		return fmt.Errorf("failed to create timer: %w", err)
+	}
+
+	return s.timers.Reload()
+}
+
+// UpdateTimer changes the settings of a timer, it stays in its channel
+func (s *Service) UpdateTimer(timer *models.Timer) error {
+	if err := timers.ValidateTimer(*timer); err != nil {
+		return err
+	}
+
+	if err := s.db.UpdateTimer(timer); err != nil {
+		return fmt.Errorf("failed to update timer: %w", err)
+	}
+
+	return s.timers.Reload()
+}
+
+func (s *Service) DeleteTimer(id int) error {
+	if err := s.db.DeleteTimer(id); err != nil {
+		return fmt.Errorf("failed to delete timer: %w", err)
+	}
+
+	return s.timers.Reload()
+}
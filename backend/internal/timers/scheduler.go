@@ -0,0 +1,256 @@
+// Package timers posts the recurring messages of channels, like a Discord
+// link every 15 minutes, while chat is active.
+package timers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"twitch-client/internal/client"
+	"twitch-client/internal/db"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+)
+
+var ErrInvalidTimer = errors.New("invalid timer")
+
+const (
+	checkInterval = 30 * time.Second
+	// How long the live status of a channel is trusted
+	liveCacheTTL       = 2 * time.Minute
+	liveLookupTimeout  = 5 * time.Second
+	maxIntervalMinutes = 24 * 60
+	// Twitch cuts chat messages at 500 characters
+	maxMessageLength = 500
+)
+
+type timerStore interface {
+	GetTimers() ([]models.Timer, error)
+	SaveTimerState(id, nextMessage int, lastSentAt time.Time) error
+}
+
+type chatClient interface {
+	HasChannel(channel string) bool
+	SendMessage(channel, message string) error
+}
+
+type streamLookup interface {
+	GetStream(ctx context.Context, login string) (*helix.Stream, error)
+}
+
+// Scheduler posts the messages of the enabled timers when they are due
+type Scheduler struct {
+	db           timerStore
+	twitchClient chatClient
+	helix        streamLookup
+	timers       map[int]*timerState
+	// chat lines seen per channel, timers compare it with the count at their last post
+	lines map[string]int
+	live  map[string]liveStatus
+	mu    sync.Mutex
+}
+
+type timerState struct {
+	timer models.Timer
+	// since is the last post, or when the timer was loaded if it never posted
+	since time.Time
+	lines int
+}
+
+type liveStatus struct {
+	live      bool
+	checkedAt time.Time
+}
+
+func NewScheduler(db *db.Database, twitchClient *client.Client, helix *helix.Client) *Scheduler {
+	return newScheduler(db, twitchClient, helix)
+}
+
+func newScheduler(db timerStore, twitchClient chatClient, helix streamLookup) *Scheduler {
+	s := &Scheduler{
+		db:           db,
+		twitchClient: twitchClient,
+		helix:        helix,
+		timers:       make(map[int]*timerState),
+		lines:        make(map[string]int),
+		live:         make(map[string]liveStatus),
+	}
+
+	if err := s.Reload(); err != nil {
+		log.Printf("Failed to load timers: %v", err)
+	}
+
+	return s
+}
+
+// ValidateTimer checks a timer before it is saved
+func ValidateTimer(timer models.Timer) error {
+	if strings.TrimSpace(timer.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTimer)
+	}
+	if len(timer.Messages) == 0 {
+		return fmt.Errorf("%w: at least one message is required", ErrInvalidTimer)
+	}
+	for _, message := range timer.Messages {
+		if strings.TrimSpace(message) == "" || len(message) > maxMessageLength {
+			return fmt.Errorf("%w: messages must be 1 to %d characters long", ErrInvalidTimer, maxMessageLength)
+		}
+	}
+	if timer.IntervalMinutes < 1 || timer.IntervalMinutes > maxIntervalMinutes {
+		return fmt.Errorf("%w: interval must be between 1 and %d minutes", ErrInvalidTimer, maxIntervalMinutes)
+	}
+	if timer.MinLines < 0 {
+		return fmt.Errorf("%w: minimum number of lines can't be negative", ErrInvalidTimer)
+	}
+	return nil
+}
+
+// Reload picks up timers changed on the dashboard, the lines counted for
+// timers that are kept stay
+func (s *Scheduler) Reload() error {
+	stored, err := s.db.GetTimers()
+	if err != nil {
+		return fmt.Errorf("failed to get timers: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	timers := make(map[int]*timerState, len(stored))
+	for _, timer := range stored {
+		state := &timerState{timer: timer, since: now, lines: s.lines[timer.Channel]}
+		if previous, ok := s.timers[timer.ID]; ok {
+			state.since, state.lines = previous.since, previous.lines
+		}
+		if timer.LastSentAt != nil {
+			state.since = *timer.LastSentAt
+		}
+		timers[timer.ID] = state
+	}
+	s.timers = timers
+
+	return nil
+}
+
+// TrackMessage counts a chat line of a channel
+func (s *Scheduler) TrackMessage(channel string) {
+	s.mu.Lock()
+	s.lines[channel]++
+	s.mu.Unlock()
+}
+
+// Remove forgets what was counted for a channel the bot left
+func (s *Scheduler) Remove(channel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.lines, channel)
+	delete(s.live, channel)
+	for _, state := range s.timers {
+		if state.timer.Channel == channel {
+			state.lines = 0
+		}
+	}
+}
+
+// Run posts the due timers until the process exits
+func (s *Scheduler) Run() {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		s.check(now)
+	}
+}
+
+// check posts the due timers, one per channel and check. Other due timers
+// follow on the next checks.
+func (s *Scheduler) check(now time.Time) {
+	posted := make(map[string]bool)
+	for _, timer := range s.due(now) {
+		if posted[timer.Channel] || timer.LiveOnly && !s.isLive(timer.Channel, now) {
+			continue
+		}
+		if s.post(timer, now) {
+			posted[timer.Channel] = true
+		}
+	}
+}
+
+// due returns the enabled timers whose interval passed with enough chat lines since their last post
+func (s *Scheduler) due(now time.Time) []models.Timer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []models.Timer
+	for _, state := range s.timers {
+		timer := state.timer
+		if !timer.Enabled || len(timer.Messages) == 0 || !s.twitchClient.HasChannel(timer.Channel) {
+			continue
+		}
+
+		interval := time.Duration(timer.IntervalMinutes) * time.Minute
+		if now.Sub(state.since) < interval || s.lines[timer.Channel]-state.lines < timer.MinLines {
+			continue
+		}
+
+		due = append(due, timer)
+	}
+
+	return due
+}
+
+// post sends the next message of a timer and moves its rotation on
+func (s *Scheduler) post(timer models.Timer, now time.Time) bool {
+	index := timer.NextMessage % len(timer.Messages)
+	if err := s.twitchClient.SendMessage(timer.Channel, timer.Messages[index]); err != nil {
+		log.Printf("Failed to post timer %q in %s: %v", timer.Name, timer.Channel, err)
+		return false
+	}
+
+	next := (index + 1) % len(timer.Messages)
+
+	s.mu.Lock()
+	if state, ok := s.timers[timer.ID]; ok {
+		state.timer.NextMessage = next
+		state.since = now
+		state.lines = s.lines[timer.Channel]
+	}
+	s.mu.Unlock()
+
+	if err := s.db.SaveTimerState(timer.ID, next, now); err != nil {
+		log.Printf("Failed to save state of timer %q: %v", timer.Name, err)
+	}
+
+	return true
+}
+
+// isLive tells whether a channel is streaming, the answer is cached for a while
+func (s *Scheduler) isLive(channel string, now time.Time) bool {
+	s.mu.Lock()
+	status, ok := s.live[channel]
+	s.mu.Unlock()
+	if ok && now.Sub(status.checkedAt) < liveCacheTTL {
+		return status.live
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), liveLookupTimeout)
+	defer cancel()
+
+	stream, err := s.helix.GetStream(ctx, channel)
+	if err != nil {
+		log.Printf("Failed to check whether %s is live: %v", channel, err)
+		return false
+	}
+
+	s.mu.Lock()
+	s.live[channel] = liveStatus{live: stream != nil, checkedAt: now}
+	s.mu.Unlock()
+
+	return stream != nil
+}
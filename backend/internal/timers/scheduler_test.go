@@ -0,0 +1,218 @@
+package timers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+	"twitch-client/internal/db/models"
+	"twitch-client/internal/helix"
+)
+
+type fakeStore struct {
+	timers []models.Timer
+	// saved is the last rotation state saved per timer
+	saved map[int]int
+}
+
+func (s *fakeStore) GetTimers() ([]models.Timer, error) { return s.timers, nil }
+
+func (s *fakeStore) SaveTimerState(id, nextMessage int, lastSentAt time.Time) error {
+	s.saved[id] = nextMessage
+	return nil
+}
+
+type sentMessage struct{ channel, message string }
+
+type fakeChat struct {
+	channels []string
+	sent     []sentMessage
+	fail     bool
+}
+
+func (c *fakeChat) HasChannel(channel string) bool { return slices.Contains(c.channels, channel) }
+
+func (c *fakeChat) SendMessage(channel, message string) error {
+	if c.fail {
+		return errors.New("not connected")
+	}
+	c.sent = append(c.sent, sentMessage{channel, message})
+	return nil
+}
+
+type fakeStreams struct {
+	live    map[string]bool
+	lookups int
+}
+
+func (f *fakeStreams) GetStream(ctx context.Context, login string) (*helix.Stream, error) {
+	f.lookups++
+	if f.live[login] {
+		return &helix.Stream{UserLogin: login}, nil
+	}
+	return nil, nil
+}
+
+func newTestScheduler(timers ...models.Timer) (*Scheduler, *fakeStore, *fakeChat, *fakeStreams) {
+	store := &fakeStore{timers: timers, saved: make(map[int]int)}
+	chat := &fakeChat{channels: []string{"streamer", "other"}}
+	streams := &fakeStreams{live: make(map[string]bool)}
+	return newScheduler(store, chat, streams), store, chat, streams
+}
+
+func TestSchedulerDue(t *testing.T) {
+	tests := []struct {
+		name    string
+		timer   models.Timer
+		elapsed time.Duration
+		lines   int
+		want    bool
+	}{
+		{"interval passed", models.Timer{IntervalMinutes: 15}, 15 * time.Minute, 0, true},
+		{"interval not passed", models.Timer{IntervalMinutes: 15}, 14 * time.Minute, 100, false},
+		{"enough lines", models.Timer{IntervalMinutes: 5, MinLines: 10}, time.Hour, 10, true},
+		{"too few lines", models.Timer{IntervalMinutes: 5, MinLines: 10}, time.Hour, 9, false},
+		{"disabled", models.Timer{IntervalMinutes: 5, Enabled: false}, time.Hour, 0, false},
+		{"no messages", models.Timer{IntervalMinutes: 5, Messages: []string{}}, time.Hour, 0, false},
+		{"channel not joined", models.Timer{IntervalMinutes: 5, Channel: "elsewhere"}, time.Hour, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timer := tt.timer
+			timer.ID = 1
+			if timer.Channel == "" {
+				timer.Channel = "streamer"
+			}
+			if timer.Messages == nil {
+				timer.Messages = []string{"Discord: example.gg"}
+			}
+			timer.Enabled = tt.name != "disabled"
+
+			s, _, _, _ := newTestScheduler(timer)
+			for i := 0; i < tt.lines; i++ {
+				s.TrackMessage(timer.Channel)
+			}
+
+			due := s.due(time.Now().Add(tt.elapsed))
+			if got := len(due) == 1; got != tt.want {
+				t.Errorf("due = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerPostRotation(t *testing.T) {
+	timer := models.Timer{ID: 1, Channel: "streamer", Enabled: true, IntervalMinutes: 1,
+		Messages: []string{"first", "second", "third"}, NextMessage: 1}
+	s, store, chat, _ := newTestScheduler(timer)
+
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		now = now.Add(time.Minute)
+		s.check(now)
+	}
+
+	var got []string
+	for _, sent := range chat.sent {
+		got = append(got, sent.message)
+	}
+	if want := []string{"second", "third", "first", "second"}; !slices.Equal(got, want) {
+		t.Errorf("posted %v, want %v", got, want)
+	}
+	if store.saved[1] != 2 {
+		t.Errorf("expected the rotation to continue at 2 after a restart, saved %d", store.saved[1])
+	}
+
+	// A failed post doesn't move the rotation on and the timer stays due
+	chat.fail = true
+	s.check(now.Add(time.Minute))
+	chat.fail = false
+	s.check(now.Add(time.Minute))
+	if last := chat.sent[len(chat.sent)-1].message; last != "third" || len(chat.sent) != 5 {
+		t.Errorf("expected the failed message to be posted next, got %q after %d posts", last, len(chat.sent))
+	}
+}
+
+func TestSchedulerOnePostPerChannel(t *testing.T) {
+	timer := func(id int, channel string) models.Timer {
+		return models.Timer{ID: id, Channel: channel, Enabled: true, IntervalMinutes: 1, Messages: []string{channel}}
+	}
+	s, _, chat, _ := newTestScheduler(timer(1, "streamer"), timer(2, "streamer"), timer(3, "other"))
+
+	now := time.Now().Add(time.Minute)
+	s.check(now)
+	if len(chat.sent) != 2 || chat.sent[0].channel == chat.sent[1].channel {
+		t.Fatalf("expected one post in each channel, got %v", chat.sent)
+	}
+
+	// The other timer of the channel follows on the next check
+	s.check(now.Add(checkInterval))
+	if len(chat.sent) != 3 || chat.sent[2].channel != "streamer" {
+		t.Errorf("expected the second timer of streamer next, got %v", chat.sent)
+	}
+}
+
+func TestSchedulerLiveOnly(t *testing.T) {
+	timer := models.Timer{ID: 1, Channel: "streamer", Enabled: true, IntervalMinutes: 1, LiveOnly: true, Messages: []string{"hi"}}
+	s, _, chat, streams := newTestScheduler(timer)
+
+	now := time.Now().Add(time.Minute)
+	s.check(now)
+	if len(chat.sent) != 0 {
+		t.Fatal("expected no post while offline")
+	}
+
+	// The offline status is trusted for a while
+	streams.live["streamer"] = true
+	s.check(now.Add(liveCacheTTL / 2))
+	if len(chat.sent) != 0 || streams.lookups != 1 {
+		t.Fatalf("expected the cached status to be used, got %d posts after %d lookups", len(chat.sent), streams.lookups)
+	}
+
+	s.check(now.Add(liveCacheTTL))
+	if len(chat.sent) != 1 || streams.lookups != 2 {
+		t.Errorf("expected a post once the stream went live, got %d posts after %d lookups", len(chat.sent), streams.lookups)
+	}
+}
+
+func TestSchedulerReloadKeepsState(t *testing.T) {
+	timer := models.Timer{ID: 1, Channel: "streamer", Enabled: true, IntervalMinutes: 10, MinLines: 5, Messages: []string{"hi"}}
+	s, store, _, _ := newTestScheduler(timer)
+	loadedAt := s.timers[1].since
+
+	for i := 0; i < 3; i++ {
+		s.TrackMessage("streamer")
+	}
+
+	// An edited timer keeps its interval start and line count, a new one
+	// counts from the reload
+	timer.MinLines = 3
+	added := models.Timer{ID: 2, Channel: "streamer", Enabled: true, IntervalMinutes: 10, MinLines: 3, Messages: []string{"new"}}
+	store.timers = []models.Timer{timer, added}
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.timers[1].since.Equal(loadedAt) || s.timers[1].lines != 0 {
+		t.Errorf("expected the edited timer to keep its state, got %+v", s.timers[1])
+	}
+	if s.timers[2].lines != 3 {
+		t.Errorf("expected the new timer to count lines from the reload, got %d", s.timers[2].lines)
+	}
+
+	due := s.due(loadedAt.Add(10 * time.Minute))
+	if len(due) != 1 || due[0].ID != 1 {
+		t.Errorf("expected only the edited timer to be due, got %v", due)
+	}
+
+	// The last post stored in the database wins
+	sentAt := time.Now().Add(-time.Hour)
+	timer.LastSentAt = &sentAt
+	store.timers = []models.Timer{timer}
+	s.Reload()
+	if !s.timers[1].since.Equal(sentAt) {
+		t.Errorf("expected the stored last post, got %v", s.timers[1].since)
+	}
+}
@@ -0,0 +1,38 @@
+package trends
+
+import "sync"
+
+// ChannelTrends keeps a separate TrendTracker for every channel
+type ChannelTrends struct {
+	trackers map[string]*TrendTracker
+	maxItems int
+	mutex    sync.Mutex
+}
+
+func NewChannelTrends(maxItems int) *ChannelTrends {
+	return &ChannelTrends{
+		trackers: make(map[string]*TrendTracker),
+		maxItems: maxItems,
+	}
+}
+
+// For returns the tracker of a channel, creating it on first use
+func (c *ChannelTrends) For(channel string) *TrendTracker {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	tracker, ok := c.trackers[channel]
+	if !ok {
+		tracker = NewTrendTracker(c.maxItems)
+		c.trackers[channel] = tracker
+	}
+	return tracker
+}
+
+// Remove drops the trends of a channel the bot left
+func (c *ChannelTrends) Remove(channel string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.trackers, channel)
+}
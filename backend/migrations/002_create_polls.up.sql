@@ -0,0 +1,11 @@
+CREATE TABLE polls (
+    id SERIAL PRIMARY KEY,
+    question TEXT NOT NULL,
+    options JSONB NOT NULL DEFAULT '[]',
+    total_votes INTEGER DEFAULT 0,
+    duration_seconds INTEGER DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_polls_ended_at ON polls (ended_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE polls ADD COLUMN mode VARCHAR(16) NOT NULL DEFAULT 'chat';
@@ -0,0 +1,11 @@
+CREATE TABLE credentials (
+    name VARCHAR(32) PRIMARY KEY,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_credentials_updated_at
+    BEFORE UPDATE ON credentials
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
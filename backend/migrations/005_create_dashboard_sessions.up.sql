@@ -0,0 +1,9 @@
+CREATE TABLE dashboard_sessions (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id VARCHAR(32) NOT NULL,
+    login VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_dashboard_sessions_expires_at ON dashboard_sessions (expires_at);
@@ -0,0 +1,13 @@
+CREATE TABLE dashboard_users (
+    user_id VARCHAR(32) PRIMARY KEY,
+    login VARCHAR(64) NOT NULL,
+    role VARCHAR(16) NOT NULL DEFAULT 'viewer',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT dashboard_users_role_check CHECK (role IN ('viewer', 'moderator', 'editor', 'owner'))
+);
+
+CREATE TRIGGER update_dashboard_users_updated_at
+    BEFORE UPDATE ON dashboard_users
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,12 @@
+CREATE TABLE channels (
+    name VARCHAR(64) PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Commands without a channel are shared by every channel
+ALTER TABLE commands ADD COLUMN channel VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE commands DROP CONSTRAINT commands_name_key;
+ALTER TABLE commands ADD CONSTRAINT commands_channel_name_key UNIQUE (channel, name);
+
+ALTER TABLE polls ADD COLUMN channel VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX idx_polls_channel_ended_at ON polls (channel, ended_at DESC);
@@ -0,0 +1,17 @@
+CREATE TABLE chat_messages (
+    id BIGSERIAL PRIMARY KEY,
+    message_id VARCHAR(64) UNIQUE NOT NULL,
+    channel VARCHAR(64) NOT NULL,
+    user_id VARCHAR(32) NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    display_name VARCHAR(64) NOT NULL DEFAULT '',
+    color VARCHAR(16) NOT NULL DEFAULT '',
+    badges JSONB NOT NULL DEFAULT '{}',
+    emotes JSONB NOT NULL DEFAULT '[]',
+    message TEXT NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_chat_messages_channel_sent_at ON chat_messages (channel, sent_at DESC);
+CREATE INDEX idx_chat_messages_user_id_sent_at ON chat_messages (user_id, sent_at DESC);
+CREATE INDEX idx_chat_messages_username ON chat_messages (username);
@@ -0,0 +1,18 @@
+ALTER TABLE chat_messages
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', message)) STORED;
+
+CREATE INDEX idx_chat_messages_search_vector ON chat_messages USING GIN (search_vector);
+
+CREATE TABLE moderation_actions (
+    id SERIAL PRIMARY KEY,
+    channel VARCHAR(64) NOT NULL,
+    user_id VARCHAR(32) NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    duration_seconds INTEGER NOT NULL DEFAULT 0,
+    moderator VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_moderation_actions_user_id ON moderation_actions (user_id, created_at DESC);
@@ -0,0 +1,22 @@
+CREATE TABLE automod_rules (
+    id SERIAL PRIMARY KEY,
+    channel VARCHAR(64) NOT NULL DEFAULT '',
+    name VARCHAR(100) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    priority INTEGER NOT NULL DEFAULT 0,
+    conditions JSONB NOT NULL DEFAULT '{}',
+    action VARCHAR(16) NOT NULL,
+    duration_seconds INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_automod_rules_updated_at
+    BEFORE UPDATE ON automod_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- The rule that used to be hard-coded in the message interceptor
+INSERT INTO automod_rules (name, conditions, action, reason)
+VALUES ('Spam bots', '{"pattern": "remove the space", "first_message": true}', 'ban', 'Banned by bot');
@@ -0,0 +1,12 @@
+ALTER TABLE moderation_actions
+    ADD COLUMN triggered_by VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN rule_id INTEGER REFERENCES automod_rules (id) ON DELETE SET NULL,
+    ADD COLUMN message_id VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN message TEXT NOT NULL DEFAULT '',
+    ADD COLUMN succeeded BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN helix_status INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN helix_response TEXT NOT NULL DEFAULT '',
+    ADD COLUMN undone_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN undone_by VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_moderation_actions_channel_created_at ON moderation_actions (channel, created_at DESC);
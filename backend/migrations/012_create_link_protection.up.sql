@@ -0,0 +1,25 @@
+CREATE TABLE link_protection (
+    channel VARCHAR(64) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    action VARCHAR(16) NOT NULL DEFAULT 'delete',
+    duration_seconds INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    exempt_subscribers BOOLEAN NOT NULL DEFAULT false,
+    exempt_vips BOOLEAN NOT NULL DEFAULT true,
+    exempt_moderators BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_link_protection_updated_at
+    BEFORE UPDATE ON link_protection
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE link_allowlist (
+    id SERIAL PRIMARY KEY,
+    channel VARCHAR(64) NOT NULL,
+    domain VARCHAR(253) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (channel, domain)
+);
@@ -0,0 +1,18 @@
+CREATE TABLE phrase_lists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_phrase_lists_updated_at
+    BEFORE UPDATE ON phrase_lists
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE banned_phrases (
+    id SERIAL PRIMARY KEY,
+    list_id INTEGER NOT NULL REFERENCES phrase_lists (id) ON DELETE CASCADE,
+    phrase VARCHAR(200) NOT NULL,
+    UNIQUE (list_id, phrase)
+);
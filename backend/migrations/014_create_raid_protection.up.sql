@@ -0,0 +1,16 @@
+CREATE TABLE raid_protection (
+    channel VARCHAR(64) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    mode VARCHAR(16) NOT NULL DEFAULT 'followers',
+    followers_duration_minutes INTEGER NOT NULL DEFAULT 10,
+    join_threshold INTEGER NOT NULL DEFAULT 30,
+    similar_threshold INTEGER NOT NULL DEFAULT 5,
+    calm_minutes INTEGER NOT NULL DEFAULT 3,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_raid_protection_updated_at
+    BEFORE UPDATE ON raid_protection
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
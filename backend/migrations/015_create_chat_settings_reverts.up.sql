@@ -0,0 +1,7 @@
+CREATE TABLE chat_settings_reverts (
+    channel VARCHAR(64) NOT NULL,
+    mode VARCHAR(16) NOT NULL,
+    settings JSONB NOT NULL,
+    revert_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (channel, mode)
+);
@@ -0,0 +1,6 @@
+-- Aliases resolve to the command, e.g. !dc for !discord
+ALTER TABLE commands ADD COLUMN aliases TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX idx_commands_aliases ON commands USING GIN (aliases);
+
+-- Shown by ${count}, raised every time a response using it is sent
+ALTER TABLE commands ADD COLUMN count INTEGER NOT NULL DEFAULT 0;
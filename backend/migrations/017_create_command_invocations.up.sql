@@ -0,0 +1,11 @@
+-- Every use of a command, built-in commands included, for the usage statistics
+CREATE TABLE command_invocations (
+    id BIGSERIAL PRIMARY KEY,
+    channel VARCHAR(64) NOT NULL,
+    command VARCHAR(50) NOT NULL,
+    user_id VARCHAR(32) NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    invoked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_command_invocations_channel_command ON command_invocations (channel, command);
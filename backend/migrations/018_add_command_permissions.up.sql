@@ -0,0 +1,3 @@
+ALTER TABLE commands ADD COLUMN permission_level VARCHAR(16) NOT NULL DEFAULT 'everyone';
+ALTER TABLE commands ADD COLUMN cooldown_mode VARCHAR(16) NOT NULL DEFAULT 'user';
+ALTER TABLE commands ADD COLUMN cooldown_warning BOOLEAN NOT NULL DEFAULT true;
@@ -0,0 +1,21 @@
+CREATE TABLE timers (
+    id SERIAL PRIMARY KEY,
+    channel VARCHAR(64) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    messages TEXT[] NOT NULL DEFAULT '{}',
+    interval_minutes INTEGER NOT NULL DEFAULT 15,
+    min_lines INTEGER NOT NULL DEFAULT 0,
+    live_only BOOLEAN NOT NULL DEFAULT false,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    -- Where the rotation through the messages continues
+    next_message INTEGER NOT NULL DEFAULT 0,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (channel, name)
+);
+
+CREATE TRIGGER update_timers_updated_at
+    BEFORE UPDATE ON timers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
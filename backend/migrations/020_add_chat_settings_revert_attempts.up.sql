@@ -0,0 +1,3 @@
+-- Failed reverts are retried, the dashboard shows how often and why they failed
+ALTER TABLE chat_settings_reverts ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE chat_settings_reverts ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Reverts of raid protection, the raid is forgotten on restart so they run right away then
+ALTER TABLE chat_settings_reverts ADD COLUMN raid BOOLEAN NOT NULL DEFAULT FALSE;
@@ -21,7 +21,7 @@ export const ChannelSelector: React.FC = () => {
     const channel = formData.get("current-channel") as string;
     try {
       const response = await fetch(
-        "http://localhost:42069/api/channel/change",
+        "http://localhost:42069/api/channel/add",
         {
           method: "POST",
           headers: {
@@ -18,14 +18,15 @@ export const useChannel = () => {
         return;
       }
 
-      if (!response.data.channel) {
+      // the dashboard shows the first of the channels the bot is in
+      const channel = response.data.channels?.[0];
+
+      if (!channel) {
         console.warn("No channel found in response");
         setChannel("");
         return;
       }
 
-      const channel = response.data.channel;
-
       if (typeof channel !== "string") {
         console.error("Invalid channel type:", channel);
         return;
@@ -9,6 +9,7 @@ import {
 } from "@components/ui/dialog";
 import { Tabs, TabsContent, TabsList, TabsTrigger } from "@components/ui/tabs";
 import { Card } from "@components/ui/card";
+import { useChannelStore } from "@/features/channel/channel-store";
 
 type Emote = {
   emote: string;
@@ -41,14 +42,20 @@ function Statistics() {
   const [topPhrases, setTopPhrases] = useState<Phrase[]>([]);
   const [topUsers, setTopUsers] = useState<UserEngagement[]>([]);
   const [isLoading, setIsLoading] = useState(true);
+  const { channel } = useChannelStore();
 
   useEffect(() => {
     const fetchData = async () => {
       setIsLoading(true);
       try {
+        const query = `?channel=${encodeURIComponent(channel)}`;
         const [trendsResponse, usersResponse] = await Promise.all([
-          fetch("http://localhost:42069/api/trends"),
-          fetch("http://localhost:42069/api/users/top"),
+          fetch("http://localhost:42069/api/trends" + query, {
+            credentials: "include",
+          }),
+          fetch("http://localhost:42069/api/users/top" + query, {
+            credentials: "include",
+          }),
         ]);
 
         const trendsData: Response<TrendsResponse> =
@@ -71,7 +78,7 @@ function Statistics() {
     const interval = setInterval(fetchData, 60000); // Update every minute
 
     return () => clearInterval(interval);
-  }, []);
+  }, [channel]);
 
   const getTopEmotesString = () => {
     if (isLoading) return "Loading...";
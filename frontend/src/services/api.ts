@@ -1,10 +1,13 @@
 import axios from "axios";
 import { ApiService, Response } from "@/types/services/api";
+import { useChannelStore } from "@/features/channel/channel-store";
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL;
 
 const axiosInstance = axios.create({
   baseURL: API_URL,
+  // send the dashboard session cookie along with every request
+  withCredentials: true,
   headers: {
     "Content-Type": "application/json",
   },
@@ -88,7 +91,10 @@ const apiService: ApiService = {
 
   fetchCommands: async () => {
     try {
-      const response = await axiosInstance.get("/commands");
+      const { channel } = useChannelStore.getState();
+      const response = await axiosInstance.get("/commands", {
+        params: { channel },
+      });
       return response.data;
     } catch (error) {
       console.error("Error fetching commands:", error);
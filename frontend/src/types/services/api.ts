@@ -8,7 +8,7 @@ export interface Response<T> {
 
 export type ApiService = {
   fetchStreamInfo: (channel: string) => Promise<Response<StreamInfo>>;
-  fetchCurrentChannel: () => Promise<Response<{ channel: string }>>;
+  fetchCurrentChannel: () => Promise<Response<{ channels: string[] }>>;
   fetchBroadcasterId: (channel: string) => Promise<Response<{ id: number }>>;
   fetchCommands: () => Promise<Response<Command[]>>;
   updateCommand: (command: Partial<Command>) => Promise<Response<Command>>;